@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/support/datastore"
+	"github.com/withObsrvr/pluginapi"
+)
+
+// BackfillConfig describes a ledger range to regenerate effects for from
+// galexie ledger files in a local directory
+type BackfillConfig struct {
+	DataStorePath     string        // Directory laid out like a galexie bucket
+	LedgersPerFile    uint32        // Ledgers per file, as configured in galexie
+	FilesPerPartition uint32        // Files per partition directory, as configured in galexie
+	StartLedger       uint32        // First ledger of the range
+	EndLedger         uint32        // Last ledger of the range, inclusive
+	Network           string        // Configured network of the files, the default network when empty
+	BufferSize        uint32        // Ledger files read ahead
+	NumWorkers        uint32        // Parallel file readers, at most BufferSize
+	RetryLimit        uint32        // Retries per file read
+	RetryWait         time.Duration // Wait between retries
+}
+
+// DefaultBackfillConfig returns the galexie defaults for the file layout and
+// ledger buffering
+func DefaultBackfillConfig() BackfillConfig {
+	return BackfillConfig{
+		LedgersPerFile:    1,
+		FilesPerPartition: 64000,
+		BufferSize:        100,
+		NumWorkers:        10,
+		RetryLimit:        3,
+		RetryWait:         5 * time.Second,
+	}
+}
+
+// validate checks the range and layout of the backfill
+func (c BackfillConfig) validate() error {
+	if c.DataStorePath == "" {
+		return errors.New("datastore path is required")
+	}
+	if c.StartLedger < 2 {
+		return errors.Errorf("start ledger %d must be at least 2", c.StartLedger)
+	}
+	if c.EndLedger < c.StartLedger {
+		return errors.Errorf("end ledger %d is before start ledger %d", c.EndLedger, c.StartLedger)
+	}
+	if c.LedgersPerFile == 0 {
+		return errors.New("ledgers per file must be greater than 0")
+	}
+	return nil
+}
+
+// Backfill reads every ledger of the configured range from local ledger files
+// and pushes the derived effects to the registered consumers, in ledger order.
+// It stops at the first consumer error, so no effect is silently dropped.
+// The processor must be initialized first.
+func (p *EffectsProcessor) Backfill(ctx context.Context, config BackfillConfig) error {
+	if err := config.validate(); err != nil {
+		return NewProcessorError(err, ErrorTypeConfiguration, ErrorSeverityError)
+	}
+
+	net, err := p.lookupNetwork(config.Network)
+	if err != nil {
+		return NewProcessorError(err, ErrorTypeConfiguration, ErrorSeverityError)
+	}
+
+	store, err := newFilesystemDataStore(config.DataStorePath, datastore.DataStoreSchema{
+		LedgersPerFile:    config.LedgersPerFile,
+		FilesPerPartition: config.FilesPerPartition,
+	})
+	if err != nil {
+		return NewProcessorError(err, ErrorTypeIO, ErrorSeverityError)
+	}
+
+	backend, err := ledgerbackend.NewBufferedStorageBackend(ledgerbackend.BufferedStorageBackendConfig{
+		BufferSize: config.BufferSize,
+		NumWorkers: config.NumWorkers,
+		RetryLimit: config.RetryLimit,
+		RetryWait:  config.RetryWait,
+	}, store)
+	if err != nil {
+		return NewProcessorError(
+			fmt.Errorf("error creating ledger backend: %w", err),
+			ErrorTypeConfiguration,
+			ErrorSeverityError,
+		)
+	}
+	defer backend.Close()
+
+	ledgerRange := ledgerbackend.BoundedRange(config.StartLedger, config.EndLedger)
+	if err := backend.PrepareRange(ctx, ledgerRange); err != nil {
+		return NewProcessorError(
+			fmt.Errorf("error preparing ledger range %s: %w", ledgerRange, err),
+			ErrorTypeIO,
+			ErrorSeverityError,
+		)
+	}
+
+	log.Printf("EffectsProcessor: backfilling ledgers %d-%d on %s from %s",
+		config.StartLedger, config.EndLedger, net.Name, config.DataStorePath)
+
+	for seq := config.StartLedger; seq <= config.EndLedger; seq++ {
+		lcm, err := backend.GetLedger(ctx, seq)
+		if err != nil {
+			return NewProcessorError(
+				fmt.Errorf("error reading ledger: %w", err),
+				ErrorTypeIO,
+				ErrorSeverityError,
+			).WithLedger(seq)
+		}
+
+		effects, err := p.transformLedgerToEffects(ctx, lcm, net)
+		if err != nil {
+			return ledgerProcessorError(err, seq)
+		}
+
+		msg := pluginapi.Message{
+			Metadata: map[string]interface{}{
+				"ledger_sequence": seq,
+				"source":          "backfill",
+			},
+			Timestamp: time.Now(),
+		}
+		if err := p.emitEffects(ctx, msg, effects, true); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("EffectsProcessor: backfill of ledgers %d-%d complete", config.StartLedger, config.EndLedger)
+	return nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/stellar/go/support/compressxdr"
+	"github.com/stellar/go/xdr"
+	"github.com/withObsrvr/pluginapi"
+)
+
+// compressionMetadataKey is the message metadata key naming the compression
+// of a LedgerCloseMetaBatch payload, e.g. "zstd" for galexie files
+const compressionMetadataKey = "compression"
+
+// compressorFor returns the compressor with the given name, or nil when the
+// payload is not compressed
+func compressorFor(name string) (compressxdr.Compressor, error) {
+	switch name {
+	case "", "none":
+		return nil, nil
+	case compressxdr.DefaultCompressor.Name():
+		return compressxdr.DefaultCompressor, nil
+	default:
+		return nil, errors.Errorf("unsupported compression %q", name)
+	}
+}
+
+// decodeLedgerCloseMetaBatch decompresses and unmarshals a LedgerCloseMetaBatch
+func decodeLedgerCloseMetaBatch(payload interface{}, compression string) (xdr.LedgerCloseMetaBatch, error) {
+	var batch xdr.LedgerCloseMetaBatch
+
+	data, ok := payload.([]byte)
+	if !ok {
+		return batch, errors.Errorf("expected payload to be []byte, got %T", payload)
+	}
+
+	compressor, err := compressorFor(compression)
+	if err != nil {
+		return batch, err
+	}
+
+	if compressor == nil {
+		if err := xdr.SafeUnmarshal(data, &batch); err != nil {
+			return batch, errors.Wrap(err, "error unmarshaling LedgerCloseMetaBatch XDR")
+		}
+	} else {
+		decoder := compressxdr.NewXDRDecoder(compressor, &batch)
+		if _, err := decoder.ReadFrom(bytes.NewReader(data)); err != nil {
+			return batch, errors.Wrapf(err, "error decoding %s compressed LedgerCloseMetaBatch", compression)
+		}
+	}
+
+	// Ledgers must be contiguous and match the batch bounds
+	for i, lcm := range batch.LedgerCloseMetas {
+		if expected := uint32(batch.StartSequence) + uint32(i); lcm.LedgerSequence() != expected {
+			return batch, errors.Errorf("batch ledger %d has sequence %d, expected %d", i, lcm.LedgerSequence(), expected)
+		}
+	}
+	if n := len(batch.LedgerCloseMetas); n > 0 && batch.LedgerCloseMetas[n-1].LedgerSequence() != uint32(batch.EndSequence) {
+		return batch, errors.Errorf("batch ends at ledger %d, expected %d", batch.LedgerCloseMetas[n-1].LedgerSequence(), batch.EndSequence)
+	}
+	return batch, nil
+}
+
+// processLedgerCloseMetaBatch emits the effects of every ledger in a batch
+// payload, one ledger at a time and in ledger order
+func (p *EffectsProcessor) processLedgerCloseMetaBatch(ctx context.Context, net networkConfig, msg pluginapi.Message) error {
+	var compression string
+	if value, ok := msg.Metadata[compressionMetadataKey]; ok && value != nil {
+		if compression, ok = value.(string); !ok {
+			return NewProcessorError(
+				fmt.Errorf("%s metadata must be a string, got %T", compressionMetadataKey, value),
+				ErrorTypeParsing,
+				ErrorSeverityError,
+			)
+		}
+	}
+
+	batch, err := decodeLedgerCloseMetaBatch(msg.Payload, compression)
+	if err != nil {
+		return NewProcessorError(
+			fmt.Errorf("error decoding ledger close meta batch: %w", err),
+			ErrorTypeParsing,
+			ErrorSeverityError,
+		).WithContext(compressionMetadataKey, compression)
+	}
+
+	for _, lcm := range batch.LedgerCloseMetas {
+		effects, err := p.transformLedgerToEffects(ctx, lcm, net)
+		if err != nil {
+			return ledgerProcessorError(err, lcm.LedgerSequence())
+		}
+		if err := p.emitEffects(ctx, msg, effects, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"encoding/base32"
+	"encoding/binary"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/xdr"
+)
+
+// addAssetDetails sets the details for `a` on `result` using keys with `prefix`
+func addAssetDetails(result map[string]interface{}, a xdr.Asset, prefix string) error {
+	var (
+		assetType string
+		code      string
+		issuer    string
+	)
+	if err := a.Extract(&assetType, &code, &issuer); err != nil {
+		return errors.Wrap(err, "xdr.Asset.Extract error")
+	}
+	result[prefix+"asset_type"] = assetType
+
+	if a.Type == xdr.AssetTypeAssetTypeNative {
+		return nil
+	}
+
+	result[prefix+"asset_code"] = code
+	result[prefix+"asset_issuer"] = issuer
+	return nil
+}
+
+// addLiquidityPoolAssetDetails sets the pool share asset details for the pool
+// described by `lpp`
+func addLiquidityPoolAssetDetails(result map[string]interface{}, lpp xdr.LiquidityPoolParameters) error {
+	result["asset_type"] = "liquidity_pool_shares"
+	if lpp.Type != xdr.LiquidityPoolTypeLiquidityPoolConstantProduct {
+		return errors.Errorf("unknown liquidity pool type %d", lpp.Type)
+	}
+	cp := lpp.ConstantProduct
+	poolID, err := xdr.NewPoolId(cp.AssetA, cp.AssetB, cp.Fee)
+	if err != nil {
+		return err
+	}
+	result["liquidity_pool_id"] = poolIDToString(poolID)
+	return nil
+}
+
+// addAccountAndMuxedAccountDetails sets the account for `a` under `prefix`, plus
+// the muxed address and ID when `a` is a muxed account
+func addAccountAndMuxedAccountDetails(result map[string]interface{}, a xdr.MuxedAccount, prefix string) error {
+	accountID := a.ToAccountId()
+	result[prefix] = accountID.Address()
+	if a.Type == xdr.CryptoKeyTypeKeyTypeMuxedEd25519 {
+		muxedAddress, err := a.GetAddress()
+		if err != nil {
+			return err
+		}
+		result[prefix+"_muxed"] = muxedAddress
+		muxedID, err := a.GetId()
+		if err != nil {
+			return err
+		}
+		result[prefix+"_muxed_id"] = muxedID
+	}
+	return nil
+}
+
+// tradeDetails returns the details of a claim atom from the point of view of
+// the buyer (the operation source) and of the seller (the offer owner)
+func tradeDetails(buyer xdr.MuxedAccount, seller xdr.AccountId, claim xdr.ClaimAtom) (bd map[string]interface{}, sd map[string]interface{}, err error) {
+	bd = map[string]interface{}{
+		"offer_id":      claim.OfferId(),
+		"seller":        seller.Address(),
+		"bought_amount": amount.String(claim.AmountSold()),
+		"sold_amount":   amount.String(claim.AmountBought()),
+	}
+	if err = addAssetDetails(bd, claim.AssetSold(), "bought_"); err != nil {
+		return
+	}
+	if err = addAssetDetails(bd, claim.AssetBought(), "sold_"); err != nil {
+		return
+	}
+
+	sd = map[string]interface{}{
+		"offer_id":      claim.OfferId(),
+		"bought_amount": amount.String(claim.AmountBought()),
+		"sold_amount":   amount.String(claim.AmountSold()),
+	}
+	if err = addAccountAndMuxedAccountDetails(sd, buyer, "seller"); err != nil {
+		return
+	}
+	if err = addAssetDetails(sd, claim.AssetBought(), "bought_"); err != nil {
+		return
+	}
+	if err = addAssetDetails(sd, claim.AssetSold(), "sold_"); err != nil {
+		return
+	}
+	return
+}
+
+// poolIDToString renders a liquidity pool ID as hex
+func poolIDToString(id xdr.PoolId) string {
+	return xdr.Hash(id).HexString()
+}
+
+// liquidityPoolDetails renders the state of a constant product liquidity pool
+func liquidityPoolDetails(lp *xdr.LiquidityPoolEntry) map[string]interface{} {
+	cp := lp.Body.ConstantProduct
+	return map[string]interface{}{
+		"id":               poolIDToString(lp.LiquidityPoolId),
+		"fee_bp":           uint32(cp.Params.Fee),
+		"type":             "constant_product",
+		"total_trustlines": strconv.FormatInt(int64(cp.PoolSharesTrustLineCount), 10),
+		"total_shares":     amount.String(cp.TotalPoolShares),
+		"reserves":         reserveAmounts(lp, cp.ReserveA, cp.ReserveB),
+	}
+}
+
+// reserveAmounts pairs the amounts of a pool's two reserves with their assets
+func reserveAmounts(lp *xdr.LiquidityPoolEntry, amountA, amountB xdr.Int64) []map[string]string {
+	params := lp.Body.ConstantProduct.Params
+	return []map[string]string{
+		{
+			"asset":  params.AssetA.StringCanonical(),
+			"amount": amount.String(amountA),
+		},
+		{
+			"asset":  params.AssetB.StringCanonical(),
+			"amount": amount.String(amountB),
+		},
+	}
+}
+
+// offerDetails renders an offer with its price both as a fraction and as a
+// decimal string
+func offerDetails(offer xdr.OfferEntry) (map[string]interface{}, error) {
+	details := map[string]interface{}{
+		"offer_id": int64(offer.OfferId),
+		"seller":   offer.SellerId.Address(),
+		"amount":   amount.String(offer.Amount),
+		"price":    offer.Price.String(),
+		"price_r": map[string]int32{
+			"n": int32(offer.Price.N),
+			"d": int32(offer.Price.D),
+		},
+	}
+	if err := addAssetDetails(details, offer.Selling, "selling_"); err != nil {
+		return nil, err
+	}
+	if err := addAssetDetails(details, offer.Buying, "buying_"); err != nil {
+		return nil, err
+	}
+	return details, nil
+}
+
+// setAuthFlagDetails sets every account authorization flag in `flags` to `setValue`
+func setAuthFlagDetails(flagDetails map[string]interface{}, flags xdr.AccountFlags, setValue bool) {
+	if flags.IsAuthRequired() {
+		flagDetails["auth_required_flag"] = setValue
+	}
+	if flags.IsAuthRevocable() {
+		flagDetails["auth_revocable_flag"] = setValue
+	}
+	if flags.IsAuthImmutable() {
+		flagDetails["auth_immutable_flag"] = setValue
+	}
+	if flags.IsAuthClawbackEnabled() {
+		flagDetails["auth_clawback_enabled_flag"] = setValue
+	}
+}
+
+// setTrustLineFlagDetails sets every trustline flag in `flags` to `setValue`.
+// The authorized_to_maintain_liabilites key keeps Horizon's spelling so the
+// details stay compatible with existing history_effects consumers.
+func setTrustLineFlagDetails(flagDetails map[string]interface{}, flags xdr.TrustLineFlags, setValue bool) {
+	if flags.IsAuthorized() {
+		flagDetails["authorized_flag"] = setValue
+	}
+	if flags.IsAuthorizedToMaintainLiabilitiesFlag() {
+		flagDetails["authorized_to_maintain_liabilites"] = setValue
+	}
+	if flags.IsClawbackEnabledFlag() {
+		flagDetails["clawback_enabled_flag"] = setValue
+	}
+}
+
+// setClaimableBalanceFlagDetails sets the claimable balance flags in `flags`
+func setClaimableBalanceFlagDetails(details map[string]interface{}, flags xdr.ClaimableBalanceFlags) {
+	if flags.IsClawbackEnabled() {
+		details["claimable_balance_clawback_enabled_flag"] = true
+	}
+}
+
+// contractLedgerKeyDetails decodes a contract data or contract code ledger key
+// into its key type, owning contract or wasm hash, and base64 XDR
+func contractLedgerKeyDetails(key xdr.LedgerKey) (map[string]interface{}, error) {
+	b64, err := xdr.MarshalBase64(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "error marshaling ledger key")
+	}
+	details := map[string]interface{}{
+		"ledger_key": b64,
+	}
+
+	switch key.Type {
+	case xdr.LedgerEntryTypeContractData:
+		data := key.MustContractData()
+		contractID, err := data.Contract.String()
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid contract address in ledger key")
+		}
+		details["key_type"] = "contract_data"
+		details["contract_id"] = contractID
+		details["durability"] = durabilityString(data.Durability)
+		if data.Key.Type == xdr.ScValTypeScvLedgerKeyContractInstance {
+			details["contract_instance"] = true
+		}
+	case xdr.LedgerEntryTypeContractCode:
+		details["key_type"] = "contract_code"
+		details["wasm_hash"] = key.MustContractCode().Hash.HexString()
+	default:
+		return nil, errors.Errorf("unexpected ledger key type %s", key.Type)
+	}
+	return details, nil
+}
+
+func durabilityString(d xdr.ContractDataDurability) string {
+	if d == xdr.ContractDataDurabilityTemporary {
+		return "temporary"
+	}
+	return "persistent"
+}
+
+// claimableBalanceVersionByte is the SEP-23 strkey version byte for claimable
+// balance IDs ('B...'). The vendored strkey package predates it.
+const claimableBalanceVersionByte byte = 1 << 3
+
+// addClaimableBalanceIDDetails sets the balance ID both as hex, the form used
+// by Horizon, and as a 'B...' strkey
+func addClaimableBalanceIDDetails(details map[string]interface{}, id xdr.ClaimableBalanceId) error {
+	hexID, err := xdr.MarshalHex(id)
+	if err != nil {
+		return errors.Wrap(err, "invalid claimable balance id")
+	}
+	strkeyID, err := claimableBalanceIDStrkey(id)
+	if err != nil {
+		return err
+	}
+	details["balance_id"] = hexID
+	details["balance_id_strkey"] = strkeyID
+	return nil
+}
+
+// claimableBalanceIDStrkey encodes a claimable balance ID as a SEP-23 strkey:
+// version byte, one byte ID type, the 32 byte hash and a CRC16-XModem checksum
+func claimableBalanceIDStrkey(id xdr.ClaimableBalanceId) (string, error) {
+	hash, ok := id.GetV0()
+	if !ok {
+		return "", errors.Errorf("unsupported claimable balance id type %d", id.Type)
+	}
+
+	raw := make([]byte, 0, 1+1+len(hash)+2)
+	raw = append(raw, claimableBalanceVersionByte, byte(id.Type))
+	raw = append(raw, hash[:]...)
+	raw = binary.LittleEndian.AppendUint16(raw, crc16XModem(raw))
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw), nil
+}
+
+func crc16XModem(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stellar/go/xdr"
+)
+
+func TestClaimableBalanceIDStrkey(t *testing.T) {
+	// Test vector from SEP-23
+	var hash xdr.Hash
+	if err := xdr.SafeUnmarshalHex("3f0c34bf93ad0d9971d04ccc90f705511c838aad9734a4a2fb0d7a03fc7fe89a", &hash); err != nil {
+		t.Fatal(err)
+	}
+	id := xdr.ClaimableBalanceId{Type: xdr.ClaimableBalanceIdTypeClaimableBalanceIdTypeV0, V0: &hash}
+
+	got, err := claimableBalanceIDStrkey(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "BAAD6DBUX6J22DMZOHIEZTEQ64CVCHEDRKWZONFEUL5Q26QD7R76RGR4TU"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	details := map[string]interface{}{}
+	if err := addClaimableBalanceIDDetails(details, id); err != nil {
+		t.Fatal(err)
+	}
+	if want := "000000003f0c34bf93ad0d9971d04ccc90f705511c838aad9734a4a2fb0d7a03fc7fe89a"; details["balance_id"] != want {
+		t.Errorf("balance_id: got %v, want %s", details["balance_id"], want)
+	}
+	if details["balance_id_strkey"] != got {
+		t.Errorf("balance_id_strkey: got %v, want %s", details["balance_id_strkey"], got)
+	}
+}
+
+func TestClaimableBalanceIDStrkeyUnsupportedType(t *testing.T) {
+	if _, err := claimableBalanceIDStrkey(xdr.ClaimableBalanceId{Type: 1}); err == nil {
+		t.Error("expected an error for an unsupported claimable balance id type")
+	}
+}
+
+func TestCRC16XModem(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		want  uint16
+	}{
+		{"", 0x0000},
+		{"123456789", 0x31C3}, // Standard CRC-16/XMODEM check value
+		{"A", 0x58E5},
+	} {
+		if got := crc16XModem([]byte(tc.input)); got != tc.want {
+			t.Errorf("crc16XModem(%q) = %#04x, want %#04x", tc.input, got, tc.want)
+		}
+	}
+}
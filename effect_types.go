@@ -8,16 +8,22 @@ import (
 
 // EffectOutput is a representation of an operation that aligns with the BigQuery table history_effects
 type EffectOutput struct {
-	Address        string                 `json:"address"`
-	AddressMuxed   null.String            `json:"address_muxed,omitempty"`
-	OperationID    int64                  `json:"operation_id"`
-	Details        map[string]interface{} `json:"details"`
-	Type           int32                  `json:"type"`
-	TypeString     string                 `json:"type_string"`
-	LedgerClosed   time.Time              `json:"closed_at"`
-	LedgerSequence uint32                 `json:"ledger_sequence"`
-	EffectIndex    uint32                 `json:"index"`
-	EffectId       string                 `json:"id"`
+	Address              string                 `json:"address"`
+	AddressMuxed         null.String            `json:"address_muxed,omitempty"`
+	OperationID          int64                  `json:"operation_id"`
+	Details              map[string]interface{} `json:"details"`
+	Type                 int32                  `json:"type"`
+	TypeString           string                 `json:"type_string"`
+	LedgerClosed         time.Time              `json:"closed_at"`
+	LedgerSequence       uint32                 `json:"ledger_sequence"`
+	EffectIndex          uint32                 `json:"index"`
+	EffectId             string                 `json:"id"`
+	PagingToken          string                 `json:"paging_token"`
+	TransactionHash      string                 `json:"transaction_hash"`
+	InnerTransactionHash null.String            `json:"inner_transaction_hash"`
+	FeeAccount           null.String            `json:"fee_account"`
+	FeeAccountMuxed      null.String            `json:"fee_account_muxed"`
+	Network              string                 `json:"network"`
 }
 
 // EffectType is the numeric type for an effect
@@ -77,6 +83,11 @@ const (
 	EffectContractDebited                    EffectType = 97
 	EffectExtendFootprintTtl                 EffectType = 98
 	EffectRestoreFootprint                   EffectType = 99
+
+	// Transaction-level records emitted for failed transactions, depending on
+	// the failed_transactions setting. These have no Horizon equivalent.
+	EffectTransactionFeeCharged EffectType = 200
+	EffectTransactionFailed     EffectType = 201
 )
 
 // EffectTypeNames stores a map of effect type ID and names
@@ -133,4 +144,6 @@ var EffectTypeNames = map[EffectType]string{
 	EffectContractDebited:                    "contract_debited",
 	EffectExtendFootprintTtl:                 "extend_footprint_ttl",
 	EffectRestoreFootprint:                   "restore_footprint",
+	EffectTransactionFeeCharged:              "transaction_fee_charged",
+	EffectTransactionFailed:                  "transaction_failed",
 }
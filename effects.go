@@ -0,0 +1,1293 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"sort"
+
+	"github.com/guregu/null"
+	"github.com/pkg/errors"
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/contractevents"
+	"github.com/stellar/go/xdr"
+)
+
+// effectsWrapper accumulates the effects produced by a single operation
+type effectsWrapper struct {
+	effects   []EffectOutput
+	operation *operationWrapper
+}
+
+// operationEffects derives every effect produced by the given operation
+func operationEffects(operation *operationWrapper) ([]EffectOutput, error) {
+	wrapper := &effectsWrapper{
+		effects:   []EffectOutput{},
+		operation: operation,
+	}
+
+	var err error
+	switch operation.OperationType() {
+	case xdr.OperationTypeCreateAccount:
+		err = wrapper.addAccountCreatedEffects()
+	case xdr.OperationTypePayment:
+		err = wrapper.addPaymentEffects()
+	case xdr.OperationTypePathPaymentStrictReceive:
+		err = wrapper.addPathPaymentStrictReceiveEffects()
+	case xdr.OperationTypePathPaymentStrictSend:
+		err = wrapper.addPathPaymentStrictSendEffects()
+	case xdr.OperationTypeManageSellOffer:
+		op := operation.operation.Body.MustManageSellOfferOp()
+		result := operation.OperationResult().MustManageSellOfferResult().MustSuccess()
+		err = wrapper.addManageOfferEffects(op.OfferId, result)
+	case xdr.OperationTypeManageBuyOffer:
+		op := operation.operation.Body.MustManageBuyOfferOp()
+		result := operation.OperationResult().MustManageBuyOfferResult().MustSuccess()
+		err = wrapper.addManageOfferEffects(op.OfferId, result)
+	case xdr.OperationTypeCreatePassiveSellOffer:
+		err = wrapper.addCreatePassiveSellOfferEffects()
+	case xdr.OperationTypeSetOptions:
+		err = wrapper.addSetOptionsEffects()
+	case xdr.OperationTypeChangeTrust:
+		err = wrapper.addChangeTrustEffects()
+	case xdr.OperationTypeAllowTrust:
+		err = wrapper.addAllowTrustEffects()
+	case xdr.OperationTypeSetTrustLineFlags:
+		err = wrapper.addSetTrustLineFlagsEffects()
+	case xdr.OperationTypeAccountMerge:
+		err = wrapper.addAccountMergeEffects()
+	case xdr.OperationTypeInflation:
+		err = wrapper.addInflationEffects()
+	case xdr.OperationTypeManageData:
+		err = wrapper.addManageDataEffects()
+	case xdr.OperationTypeBumpSequence:
+		err = wrapper.addBumpSequenceEffects()
+	case xdr.OperationTypeCreateClaimableBalance:
+		err = wrapper.addCreateClaimableBalanceEffects()
+	case xdr.OperationTypeClaimClaimableBalance:
+		err = wrapper.addClaimClaimableBalanceEffects()
+	case xdr.OperationTypeClawbackClaimableBalance:
+		err = wrapper.addClawbackClaimableBalanceEffects()
+	case xdr.OperationTypeClawback:
+		err = wrapper.addClawbackEffects()
+	case xdr.OperationTypeLiquidityPoolDeposit:
+		err = wrapper.addLiquidityPoolDepositEffect()
+	case xdr.OperationTypeLiquidityPoolWithdraw:
+		err = wrapper.addLiquidityPoolWithdrawEffect()
+	case xdr.OperationTypeInvokeHostFunction:
+		err = wrapper.addInvokeHostFunctionEffects(operation.contractEvents())
+	case xdr.OperationTypeExtendFootprintTtl:
+		err = wrapper.addExtendFootprintTtlEffect()
+	case xdr.OperationTypeRestoreFootprint:
+		err = wrapper.addRestoreFootprintEffect()
+	case xdr.OperationTypeBeginSponsoringFutureReserves,
+		xdr.OperationTypeEndSponsoringFutureReserves,
+		xdr.OperationTypeRevokeSponsorship:
+		// The effects of these operations are obtained indirectly from the
+		// ledger entries
+	default:
+		return nil, errors.Errorf("unknown operation type: %s", operation.OperationType())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Effects generated for multiple operations. Keep the effect categories
+	// separated so they are "together" in case of different order or meta
+	// changes generated by core (unordered_map).
+
+	// Sponsorships
+	for _, change := range operation.changes {
+		if err := wrapper.addLedgerEntrySponsorshipEffects(change); err != nil {
+			return nil, err
+		}
+		wrapper.addSignerSponsorshipEffects(change)
+	}
+
+	// Liquidity pools
+	for _, change := range operation.changes {
+		// Effects caused by ChangeTrust (creation), AllowTrust and SetTrustlineFlags (removal through revocation)
+		wrapper.addLedgerEntryLiquidityPoolEffects(change)
+	}
+
+	// Fee-bump transactions carry the inner transaction's operations, so
+	// their effects are tagged with both the outer and the inner hash.
+	txHash := operation.TransactionHash()
+	innerHash, isFeeBump := operation.transaction.InnerTransactionHash()
+	var feeAccount, feeAccountMuxed null.String
+	if isFeeBump {
+		address, _ := operation.transaction.FeeAccount()
+		feeAccount = null.StringFrom(address)
+		if muxed, ok := operation.transaction.FeeAccountMuxed(); ok {
+			feeAccountMuxed = null.StringFrom(muxed)
+		}
+	}
+
+	for i := range wrapper.effects {
+		wrapper.effects[i].TransactionHash = txHash
+		wrapper.effects[i].InnerTransactionHash = null.NewString(innerHash, isFeeBump)
+		wrapper.effects[i].FeeAccount = feeAccount
+		wrapper.effects[i].FeeAccountMuxed = feeAccountMuxed
+		wrapper.effects[i].Network = operation.networkName
+		wrapper.effects[i].LedgerClosed = operation.ledgerClosed
+		wrapper.effects[i].LedgerSequence = operation.ledgerSequence
+		wrapper.effects[i].EffectIndex = uint32(i)
+		wrapper.effects[i].EffectId = effectID(wrapper.effects[i].OperationID, wrapper.effects[i].EffectIndex)
+		wrapper.effects[i].PagingToken = pagingToken(wrapper.effects[i].OperationID, wrapper.effects[i].EffectIndex)
+	}
+
+	return wrapper.effects, nil
+}
+
+// effectID builds the "<operation toid>-<index>" identifier of stellar-etl's
+// history_effects rows, where index is 0-based.
+func effectID(operationID int64, index uint32) string {
+	return fmt.Sprintf("%d-%d", operationID, index)
+}
+
+// pagingToken builds Horizon's effect paging token, which uses the 1-based
+// order of the effect within its operation.
+func pagingToken(operationID int64, index uint32) string {
+	return fmt.Sprintf("%d-%d", operationID, index+1)
+}
+
+func (e *effectsWrapper) add(address string, addressMuxed null.String, effectType EffectType, details map[string]interface{}) {
+	e.effects = append(e.effects, EffectOutput{
+		Address:      address,
+		AddressMuxed: addressMuxed,
+		OperationID:  e.operation.ID(),
+		Details:      details,
+		Type:         int32(effectType),
+		TypeString:   EffectTypeNames[effectType],
+	})
+}
+
+func (e *effectsWrapper) addUnmuxed(address *xdr.AccountId, effectType EffectType, details map[string]interface{}) {
+	e.add(address.Address(), null.String{}, effectType, details)
+}
+
+// addMuxed adds an effect for a possibly muxed account. When the account is an
+// M... address the muxed address is stored in AddressMuxed and its ID is added
+// to a copy of the details, so maps shared between effects are left untouched.
+func (e *effectsWrapper) addMuxed(address *xdr.MuxedAccount, effectType EffectType, details map[string]interface{}) {
+	var addressMuxed null.String
+	if address.Type == xdr.CryptoKeyTypeKeyTypeMuxedEd25519 {
+		addressMuxed = null.StringFrom(address.Address())
+		muxedDetails := make(map[string]interface{}, len(details)+1)
+		for k, v := range details {
+			muxedDetails[k] = v
+		}
+		muxedDetails["address_muxed_id"] = uint64(address.Med25519.Id)
+		details = muxedDetails
+	}
+	accID := address.ToAccountId()
+	e.add(accID.Address(), addressMuxed, effectType, details)
+}
+
+var sponsoringEffectsTable = map[xdr.LedgerEntryType]struct {
+	created, updated, removed EffectType
+}{
+	xdr.LedgerEntryTypeAccount: {
+		created: EffectAccountSponsorshipCreated,
+		updated: EffectAccountSponsorshipUpdated,
+		removed: EffectAccountSponsorshipRemoved,
+	},
+	xdr.LedgerEntryTypeTrustline: {
+		created: EffectTrustlineSponsorshipCreated,
+		updated: EffectTrustlineSponsorshipUpdated,
+		removed: EffectTrustlineSponsorshipRemoved,
+	},
+	xdr.LedgerEntryTypeData: {
+		created: EffectDataSponsorshipCreated,
+		updated: EffectDataSponsorshipUpdated,
+		removed: EffectDataSponsorshipRemoved,
+	},
+	xdr.LedgerEntryTypeClaimableBalance: {
+		created: EffectClaimableBalanceSponsorshipCreated,
+		updated: EffectClaimableBalanceSponsorshipUpdated,
+		removed: EffectClaimableBalanceSponsorshipRemoved,
+	},
+
+	// We intentionally don't have Sponsoring effects for Offer
+	// entries because we don't generate creation effects for them.
+}
+
+// addSignerSponsorshipEffects diffs the per-signer sponsors of an account entry
+func (e *effectsWrapper) addSignerSponsorshipEffects(change ingest.Change) {
+	if change.Type != xdr.LedgerEntryTypeAccount {
+		return
+	}
+
+	preSigners := map[string]xdr.AccountId{}
+	postSigners := map[string]xdr.AccountId{}
+	if change.Pre != nil {
+		account := change.Pre.Data.MustAccount()
+		preSigners = account.SponsorPerSigner()
+	}
+	if change.Post != nil {
+		account := change.Post.Data.MustAccount()
+		postSigners = account.SponsorPerSigner()
+	}
+
+	var all []string
+	for signer := range preSigners {
+		all = append(all, signer)
+	}
+	for signer := range postSigners {
+		if _, ok := preSigners[signer]; ok {
+			continue
+		}
+		all = append(all, signer)
+	}
+	sort.Strings(all)
+
+	for _, signer := range all {
+		pre, foundPre := preSigners[signer]
+		post, foundPost := postSigners[signer]
+		details := map[string]interface{}{}
+
+		switch {
+		case !foundPre && !foundPost:
+			continue
+		case !foundPre && foundPost:
+			details["sponsor"] = post.Address()
+			details["signer"] = signer
+			srcAccount := change.Post.Data.MustAccount().AccountId
+			e.addUnmuxed(&srcAccount, EffectSignerSponsorshipCreated, details)
+		case !foundPost && foundPre:
+			details["former_sponsor"] = pre.Address()
+			details["signer"] = signer
+			srcAccount := change.Pre.Data.MustAccount().AccountId
+			e.addUnmuxed(&srcAccount, EffectSignerSponsorshipRemoved, details)
+		case foundPre && foundPost:
+			formerSponsor := pre.Address()
+			newSponsor := post.Address()
+			if formerSponsor == newSponsor {
+				continue
+			}
+
+			details["former_sponsor"] = formerSponsor
+			details["new_sponsor"] = newSponsor
+			details["signer"] = signer
+			srcAccount := change.Post.Data.MustAccount().AccountId
+			e.addUnmuxed(&srcAccount, EffectSignerSponsorshipUpdated, details)
+		}
+	}
+}
+
+// addLedgerEntrySponsorshipEffects diffs the sponsor of an account, trustline,
+// data or claimable balance entry
+func (e *effectsWrapper) addLedgerEntrySponsorshipEffects(change ingest.Change) error {
+	effectsForEntryType, found := sponsoringEffectsTable[change.Type]
+	if !found {
+		return nil
+	}
+
+	details := map[string]interface{}{}
+	var effectType EffectType
+
+	switch {
+	case (change.Pre == nil || change.Pre.SponsoringID() == nil) &&
+		(change.Post != nil && change.Post.SponsoringID() != nil):
+		effectType = effectsForEntryType.created
+		details["sponsor"] = (*change.Post.SponsoringID()).Address()
+	case (change.Pre != nil && change.Pre.SponsoringID() != nil) &&
+		(change.Post == nil || change.Post.SponsoringID() == nil):
+		effectType = effectsForEntryType.removed
+		details["former_sponsor"] = (*change.Pre.SponsoringID()).Address()
+	case (change.Pre != nil && change.Pre.SponsoringID() != nil) &&
+		(change.Post != nil && change.Post.SponsoringID() != nil):
+		preSponsor := (*change.Pre.SponsoringID()).Address()
+		postSponsor := (*change.Post.SponsoringID()).Address()
+		if preSponsor == postSponsor {
+			return nil
+		}
+		effectType = effectsForEntryType.updated
+		details["new_sponsor"] = postSponsor
+		details["former_sponsor"] = preSponsor
+	default:
+		return nil
+	}
+
+	var (
+		accountID    *xdr.AccountId
+		muxedAccount *xdr.MuxedAccount
+	)
+
+	var data xdr.LedgerEntryData
+	if change.Post != nil {
+		data = change.Post.Data
+	} else {
+		data = change.Pre.Data
+	}
+
+	switch change.Type {
+	case xdr.LedgerEntryTypeAccount:
+		a := data.MustAccount().AccountId
+		accountID = &a
+	case xdr.LedgerEntryTypeTrustline:
+		tl := data.MustTrustLine()
+		accountID = &tl.AccountId
+		if tl.Asset.Type == xdr.AssetTypeAssetTypePoolShare {
+			details["asset_type"] = "liquidity_pool"
+			details["liquidity_pool_id"] = poolIDToString(*tl.Asset.LiquidityPoolId)
+		} else {
+			details["asset"] = tl.Asset.ToAsset().StringCanonical()
+		}
+	case xdr.LedgerEntryTypeData:
+		muxedAccount = e.operation.SourceAccount()
+		details["data_name"] = data.MustData().DataName
+	case xdr.LedgerEntryTypeClaimableBalance:
+		muxedAccount = e.operation.SourceAccount()
+		if err := addClaimableBalanceIDDetails(details, data.MustClaimableBalance().BalanceId); err != nil {
+			return errors.Wrapf(err, "invalid balance id in change from operation %d", e.operation.index)
+		}
+	default:
+		return errors.Errorf("invalid sponsorship ledger entry type %v", change.Type.String())
+	}
+
+	if accountID != nil {
+		e.addUnmuxed(accountID, effectType, details)
+	} else {
+		e.addMuxed(muxedAccount, effectType, details)
+	}
+	return nil
+}
+
+func (e *effectsWrapper) addAccountCreatedEffects() error {
+	op := e.operation.operation.Body.MustCreateAccountOp()
+
+	e.addUnmuxed(
+		&op.Destination,
+		EffectAccountCreated,
+		map[string]interface{}{
+			"starting_balance": amount.String(op.StartingBalance),
+		},
+	)
+	e.addMuxed(
+		e.operation.SourceAccount(),
+		EffectAccountDebited,
+		map[string]interface{}{
+			"asset_type": "native",
+			"amount":     amount.String(op.StartingBalance),
+		},
+	)
+	e.addUnmuxed(
+		&op.Destination,
+		EffectSignerCreated,
+		map[string]interface{}{
+			"public_key": op.Destination.Address(),
+			"weight":     keypair.DefaultSignerWeight,
+		},
+	)
+	return nil
+}
+
+func (e *effectsWrapper) addPaymentEffects() error {
+	op := e.operation.operation.Body.MustPaymentOp()
+
+	details := map[string]interface{}{"amount": amount.String(op.Amount)}
+	if err := addAssetDetails(details, op.Asset, ""); err != nil {
+		return err
+	}
+
+	e.addMuxed(&op.Destination, EffectAccountCredited, details)
+	e.addMuxed(e.operation.SourceAccount(), EffectAccountDebited, details)
+	return nil
+}
+
+func (e *effectsWrapper) addPathPaymentStrictReceiveEffects() error {
+	op := e.operation.operation.Body.MustPathPaymentStrictReceiveOp()
+	result := e.operation.OperationResult().MustPathPaymentStrictReceiveResult()
+	source := e.operation.SourceAccount()
+
+	details := map[string]interface{}{"amount": amount.String(op.DestAmount)}
+	if err := addAssetDetails(details, op.DestAsset, ""); err != nil {
+		return err
+	}
+	e.addMuxed(&op.Destination, EffectAccountCredited, details)
+
+	details = map[string]interface{}{"amount": amount.String(result.SendAmount())}
+	if err := addAssetDetails(details, op.SendAsset, ""); err != nil {
+		return err
+	}
+	e.addMuxed(source, EffectAccountDebited, details)
+
+	return e.addIngestTradeEffects(*source, result.MustSuccess().Offers)
+}
+
+func (e *effectsWrapper) addPathPaymentStrictSendEffects() error {
+	op := e.operation.operation.Body.MustPathPaymentStrictSendOp()
+	result := e.operation.OperationResult().MustPathPaymentStrictSendResult()
+	source := e.operation.SourceAccount()
+
+	details := map[string]interface{}{"amount": amount.String(result.DestAmount())}
+	if err := addAssetDetails(details, op.DestAsset, ""); err != nil {
+		return err
+	}
+	e.addMuxed(&op.Destination, EffectAccountCredited, details)
+
+	details = map[string]interface{}{"amount": amount.String(op.SendAmount)}
+	if err := addAssetDetails(details, op.SendAsset, ""); err != nil {
+		return err
+	}
+	e.addMuxed(source, EffectAccountDebited, details)
+
+	return e.addIngestTradeEffects(*source, result.MustSuccess().Offers)
+}
+
+// addIngestTradeEffects adds the trade effects for every claim atom, in the
+// order the atoms appear in the operation result, followed by the updates or
+// removals of the order book offers that were crossed. Both path payments and
+// offer operations cross offers, so their owners get the same effects either
+// way.
+func (e *effectsWrapper) addIngestTradeEffects(buyer xdr.MuxedAccount, claims []xdr.ClaimAtom) error {
+	for _, claim := range claims {
+		if claim.AmountSold() == 0 && claim.AmountBought() == 0 {
+			continue
+		}
+		switch claim.Type {
+		case xdr.ClaimAtomTypeClaimAtomTypeLiquidityPool:
+			if err := e.addClaimLiquidityPoolTradeEffect(claim); err != nil {
+				return err
+			}
+		default:
+			if err := e.addClaimTradeEffects(buyer, claim); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, claim := range claims {
+		if claim.Type == xdr.ClaimAtomTypeClaimAtomTypeLiquidityPool {
+			continue
+		}
+		if err := e.addClaimedOfferEffect(claim.SellerId(), claim.OfferId()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *effectsWrapper) addClaimTradeEffects(buyer xdr.MuxedAccount, claim xdr.ClaimAtom) error {
+	seller := claim.SellerId()
+	bd, sd, err := tradeDetails(buyer, seller, claim)
+	if err != nil {
+		return err
+	}
+
+	e.addMuxed(&buyer, EffectTrade, bd)
+	e.addUnmuxed(&seller, EffectTrade, sd)
+	return nil
+}
+
+func (e *effectsWrapper) addClaimLiquidityPoolTradeEffect(claim xdr.ClaimAtom) error {
+	lp, _, err := e.operation.getLiquidityPoolAndProductDelta(&claim.LiquidityPool.LiquidityPoolId)
+	if err != nil {
+		return err
+	}
+	details := map[string]interface{}{
+		"liquidity_pool": liquidityPoolDetails(lp),
+		"sold": map[string]string{
+			"asset":  claim.LiquidityPool.AssetSold.StringCanonical(),
+			"amount": amount.String(claim.LiquidityPool.AmountSold),
+		},
+		"bought": map[string]string{
+			"asset":  claim.LiquidityPool.AssetBought.StringCanonical(),
+			"amount": amount.String(claim.LiquidityPool.AmountBought),
+		},
+	}
+	e.addMuxed(e.operation.SourceAccount(), EffectLiquidityPoolTrade, details)
+	return nil
+}
+
+func (e *effectsWrapper) addCreatePassiveSellOfferEffects() error {
+	result := e.operation.OperationResult()
+
+	// KNOWN ISSUE: stellar-core creates results for CreatePassiveOffer operations
+	// with the wrong result arm set.
+	var success xdr.ManageOfferSuccessResult
+	if result.Type == xdr.OperationTypeManageSellOffer {
+		success = result.MustManageSellOfferResult().MustSuccess()
+	} else {
+		success = result.MustCreatePassiveSellOfferResult().MustSuccess()
+	}
+
+	return e.addManageOfferEffects(0, success)
+}
+
+// addManageOfferEffects adds the trades for every offer claimed by a DEX
+// operation, the updates or removals of the claimed offers and the effect on
+// the offer managed by the operation itself. offerID is the ID set in the
+// operation, zero when the operation creates a new offer.
+func (e *effectsWrapper) addManageOfferEffects(offerID xdr.Int64, result xdr.ManageOfferSuccessResult) error {
+	source := e.operation.SourceAccount()
+	if err := e.addIngestTradeEffects(*source, result.OffersClaimed); err != nil {
+		return err
+	}
+
+	switch result.Offer.Effect {
+	case xdr.ManageOfferEffectManageOfferCreated:
+		details, err := offerDetails(*result.Offer.Offer)
+		if err != nil {
+			return err
+		}
+		e.addMuxed(source, EffectOfferCreated, details)
+	case xdr.ManageOfferEffectManageOfferUpdated:
+		details, err := offerDetails(*result.Offer.Offer)
+		if err != nil {
+			return err
+		}
+		e.addMuxed(source, EffectOfferUpdated, details)
+	case xdr.ManageOfferEffectManageOfferDeleted:
+		// A new offer that is fully filled on submission never existed, so
+		// only an existing offer can be removed.
+		if offerID == 0 {
+			return nil
+		}
+		details := map[string]interface{}{"offer_id": int64(offerID)}
+		if change, ok := e.operation.offerChange(source.ToAccountId(), offerID); ok && change.Pre != nil {
+			var err error
+			if details, err = offerDetails(change.Pre.Data.MustOffer()); err != nil {
+				return err
+			}
+		}
+		e.addMuxed(source, EffectOfferRemoved, details)
+	}
+	return nil
+}
+
+// addClaimedOfferEffect adds offer_updated or offer_removed for the owner of an
+// offer that was crossed, depending on whether it was partially or fully filled.
+func (e *effectsWrapper) addClaimedOfferEffect(seller xdr.AccountId, offerID xdr.Int64) error {
+	change, ok := e.operation.offerChange(seller, offerID)
+	if !ok {
+		return nil
+	}
+
+	if change.Post == nil {
+		details, err := offerDetails(change.Pre.Data.MustOffer())
+		if err != nil {
+			return err
+		}
+		e.addUnmuxed(&seller, EffectOfferRemoved, details)
+		return nil
+	}
+
+	details, err := offerDetails(change.Post.Data.MustOffer())
+	if err != nil {
+		return err
+	}
+	e.addUnmuxed(&seller, EffectOfferUpdated, details)
+	return nil
+}
+
+// addSetOptionsEffects diffs the source account entry before and after the
+// operation and adds an effect for every setting that actually changed.
+func (e *effectsWrapper) addSetOptionsEffects() error {
+	source := e.operation.SourceAccount()
+	change, ok := e.operation.accountChange(source.ToAccountId())
+	if !ok || change.Pre == nil || change.Post == nil {
+		return nil
+	}
+	before := change.Pre.Data.MustAccount()
+	after := change.Post.Data.MustAccount()
+
+	if before.HomeDomain != after.HomeDomain {
+		e.addMuxed(source, EffectAccountHomeDomainUpdated, map[string]interface{}{
+			"home_domain": string(after.HomeDomain),
+		})
+	}
+
+	thresholdDetails := map[string]interface{}{}
+	if before.ThresholdLow() != after.ThresholdLow() {
+		thresholdDetails["low_threshold"] = after.ThresholdLow()
+	}
+	if before.ThresholdMedium() != after.ThresholdMedium() {
+		thresholdDetails["med_threshold"] = after.ThresholdMedium()
+	}
+	if before.ThresholdHigh() != after.ThresholdHigh() {
+		thresholdDetails["high_threshold"] = after.ThresholdHigh()
+	}
+	if len(thresholdDetails) > 0 {
+		e.addMuxed(source, EffectAccountThresholdsUpdated, thresholdDetails)
+	}
+
+	flagDetails := map[string]interface{}{}
+	setAuthFlagDetails(flagDetails, xdr.AccountFlags(after.Flags&^before.Flags), true)
+	setAuthFlagDetails(flagDetails, xdr.AccountFlags(before.Flags&^after.Flags), false)
+	if len(flagDetails) > 0 {
+		e.addMuxed(source, EffectAccountFlagsUpdated, flagDetails)
+	}
+
+	if after.InflationDest != nil &&
+		(before.InflationDest == nil || !before.InflationDest.Equals(*after.InflationDest)) {
+		e.addMuxed(source, EffectAccountInflationDestinationUpdated, map[string]interface{}{
+			"inflation_destination": after.InflationDest.Address(),
+		})
+	}
+
+	e.addSignerEffects(source, before.SignerSummary(), after.SignerSummary())
+	return nil
+}
+
+// addSignerEffects adds signer_removed, signer_updated and signer_created
+// effects for the difference between two signer summaries. Signer keys of
+// every type (ed25519, pre-auth tx, hash-x and signed payload) are keyed by
+// their strkey.
+func (e *effectsWrapper) addSignerEffects(source *xdr.MuxedAccount, before, after map[string]int32) {
+	var beforeSortedSigners []string
+	for signer := range before {
+		beforeSortedSigners = append(beforeSortedSigners, signer)
+	}
+	sort.Strings(beforeSortedSigners)
+
+	for _, addy := range beforeSortedSigners {
+		weight, ok := after[addy]
+		if !ok {
+			e.addMuxed(source, EffectSignerRemoved, map[string]interface{}{
+				"public_key": addy,
+			})
+			continue
+		}
+		if weight != before[addy] {
+			e.addMuxed(source, EffectSignerUpdated, map[string]interface{}{
+				"public_key": addy,
+				"weight":     weight,
+			})
+		}
+	}
+
+	var afterSortedSigners []string
+	for signer := range after {
+		afterSortedSigners = append(afterSortedSigners, signer)
+	}
+	sort.Strings(afterSortedSigners)
+
+	for _, addy := range afterSortedSigners {
+		// Signers present before were handled as updates above
+		if _, ok := before[addy]; ok {
+			continue
+		}
+		e.addMuxed(source, EffectSignerCreated, map[string]interface{}{
+			"public_key": addy,
+			"weight":     after[addy],
+		})
+	}
+}
+
+func (e *effectsWrapper) addChangeTrustEffects() error {
+	source := e.operation.SourceAccount()
+	op := e.operation.operation.Body.MustChangeTrustOp()
+
+	// NOTE: when an account trusts itself, the transaction is successful but
+	// no ledger entries are actually modified.
+	for _, change := range e.operation.changes {
+		if change.Type != xdr.LedgerEntryTypeTrustline {
+			continue
+		}
+
+		var (
+			effect    EffectType
+			trustLine xdr.TrustLineEntry
+		)
+
+		switch {
+		case change.Pre == nil && change.Post != nil:
+			effect = EffectTrustlineCreated
+			trustLine = *change.Post.Data.TrustLine
+		case change.Pre != nil && change.Post == nil:
+			effect = EffectTrustlineRemoved
+			trustLine = *change.Pre.Data.TrustLine
+		case change.Pre != nil && change.Post != nil:
+			effect = EffectTrustlineUpdated
+			trustLine = *change.Post.Data.TrustLine
+		default:
+			return errors.New("invalid trustline change without pre or post state")
+		}
+
+		// We want to add a single effect for change_trust op. If it's modifying
+		// credit_asset search for credit_asset trustline, otherwise search for
+		// liquidity_pool.
+		if op.Line.Type != trustLine.Asset.Type {
+			continue
+		}
+
+		details := map[string]interface{}{"limit": amount.String(op.Limit)}
+		if trustLine.Asset.Type == xdr.AssetTypeAssetTypePoolShare {
+			// The only change_trust ops that can modify LP are those with
+			// asset=liquidity_pool so *op.Line.LiquidityPool below is available.
+			if err := addLiquidityPoolAssetDetails(details, *op.Line.LiquidityPool); err != nil {
+				return err
+			}
+		} else {
+			if err := addAssetDetails(details, op.Line.ToAsset(), ""); err != nil {
+				return err
+			}
+		}
+
+		e.addMuxed(source, effect, details)
+		break
+	}
+
+	return nil
+}
+
+// addLedgerEntryLiquidityPoolEffects adds liquidity_pool_created and
+// liquidity_pool_removed when a pool entry appears in or disappears from the meta
+func (e *effectsWrapper) addLedgerEntryLiquidityPoolEffects(change ingest.Change) {
+	if change.Type != xdr.LedgerEntryTypeLiquidityPool {
+		return
+	}
+
+	var (
+		effectType EffectType
+		details    map[string]interface{}
+	)
+	switch {
+	case change.Pre == nil && change.Post != nil:
+		effectType = EffectLiquidityPoolCreated
+		details = map[string]interface{}{
+			"liquidity_pool": liquidityPoolDetails(change.Post.Data.LiquidityPool),
+		}
+	case change.Pre != nil && change.Post == nil:
+		effectType = EffectLiquidityPoolRemoved
+		details = map[string]interface{}{
+			"liquidity_pool_id": poolIDToString(change.Pre.Data.LiquidityPool.LiquidityPoolId),
+		}
+	default:
+		return
+	}
+	e.addMuxed(e.operation.SourceAccount(), effectType, details)
+}
+
+func (e *effectsWrapper) addAllowTrustEffects() error {
+	source := e.operation.SourceAccount()
+	op := e.operation.operation.Body.MustAllowTrustOp()
+	asset := op.Asset.ToAsset(source.ToAccountId())
+
+	var setFlags, clearFlags *xdr.Uint32
+	switch {
+	case xdr.TrustLineFlags(op.Authorize).IsAuthorized():
+		flags := xdr.Uint32(xdr.TrustLineFlagsAuthorizedFlag)
+		setFlags = &flags
+	case xdr.TrustLineFlags(op.Authorize).IsAuthorizedToMaintainLiabilitiesFlag():
+		flags := xdr.Uint32(xdr.TrustLineFlagsAuthorizedToMaintainLiabilitiesFlag)
+		setFlags = &flags
+	default:
+		// Deauthorizing clears both authorization levels
+		flags := xdr.Uint32(xdr.TrustLineFlagsAuthorizedFlag | xdr.TrustLineFlagsAuthorizedToMaintainLiabilitiesFlag)
+		clearFlags = &flags
+	}
+
+	if err := e.addTrustLineFlagsEffect(source, &op.Trustor, asset, setFlags, clearFlags); err != nil {
+		return err
+	}
+	return e.addLiquidityPoolRevokedEffect()
+}
+
+func (e *effectsWrapper) addSetTrustLineFlagsEffects() error {
+	source := e.operation.SourceAccount()
+	op := e.operation.operation.Body.MustSetTrustLineFlagsOp()
+	if err := e.addTrustLineFlagsEffect(source, &op.Trustor, op.Asset, &op.SetFlags, &op.ClearFlags); err != nil {
+		return err
+	}
+	return e.addLiquidityPoolRevokedEffect()
+}
+
+func (e *effectsWrapper) addTrustLineFlagsEffect(
+	account *xdr.MuxedAccount,
+	trustor *xdr.AccountId,
+	asset xdr.Asset,
+	setFlags *xdr.Uint32,
+	clearFlags *xdr.Uint32,
+) error {
+	details := map[string]interface{}{
+		"trustor": trustor.Address(),
+	}
+	if err := addAssetDetails(details, asset, ""); err != nil {
+		return err
+	}
+
+	var flagDetailsAdded bool
+	if setFlags != nil {
+		setTrustLineFlagDetails(details, xdr.TrustLineFlags(*setFlags), true)
+		flagDetailsAdded = true
+	}
+	if clearFlags != nil {
+		setTrustLineFlagDetails(details, xdr.TrustLineFlags(*clearFlags), false)
+		flagDetailsAdded = true
+	}
+
+	if flagDetailsAdded {
+		e.addMuxed(account, EffectTrustlineFlagsUpdated, details)
+	}
+	return nil
+}
+
+// sortableClaimableBalanceEntries orders claimable balances by asset and then
+// by balance ID, as revoking several pools can create balances of one asset
+type sortableClaimableBalanceEntries []*xdr.ClaimableBalanceEntry
+
+func (s sortableClaimableBalanceEntries) Len() int      { return len(s) }
+func (s sortableClaimableBalanceEntries) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s sortableClaimableBalanceEntries) Less(i, j int) bool {
+	if !s[i].Asset.Equals(s[j].Asset) {
+		return s[i].Asset.LessThan(s[j].Asset)
+	}
+	idI, _ := s[i].BalanceId.GetV0()
+	idJ, _ := s[j].BalanceId.GetV0()
+	return bytes.Compare(idI[:], idJ[:]) < 0
+}
+
+// addLiquidityPoolRevokedEffect adds, when revoking authorization forced the
+// trustor's pool shares to be redeemed, the claimable balances holding the
+// redeemed reserves followed by one liquidity_pool_revoked per redeemed pool.
+// Every pool-share trustline containing the revoked asset is redeemed, so a
+// single operation can revoke several pools.
+func (e *effectsWrapper) addLiquidityPoolRevokedEffect() error {
+	source := e.operation.SourceAccount()
+
+	createdBalances := map[string]*xdr.ClaimableBalanceEntry{}
+	var cbs sortableClaimableBalanceEntries
+	for _, change := range e.operation.changes {
+		if change.Type == xdr.LedgerEntryTypeClaimableBalance && change.Pre == nil && change.Post != nil {
+			cb := change.Post.Data.ClaimableBalance
+			id, err := xdr.MarshalHex(cb.BalanceId)
+			if err != nil {
+				return err
+			}
+			createdBalances[id] = cb
+			cbs = append(cbs, cb)
+		}
+	}
+	if len(cbs) == 0 {
+		// no claimable balances were created, and thus, no revocation happened
+		return nil
+	}
+	// Core's claimable balance metadata isn't ordered, so we order it ourselves
+	// so that effects are ordered consistently
+	sort.Sort(cbs)
+	for _, cb := range cbs {
+		if err := e.addClaimableBalanceEntryCreatedEffects(source, cb); err != nil {
+			return err
+		}
+	}
+
+	// Pool changes are ordered by pool ID for the same reason
+	var poolChanges []ingest.Change
+	for _, change := range e.operation.changes {
+		if change.Type == xdr.LedgerEntryTypeLiquidityPool {
+			poolChanges = append(poolChanges, change)
+		}
+	}
+	sort.Slice(poolChanges, func(i, j int) bool {
+		return poolIDToString(liquidityPoolChangeID(poolChanges[i])) < poolIDToString(liquidityPoolChangeID(poolChanges[j]))
+	})
+
+	for _, change := range poolChanges {
+		lp, delta, err := liquidityPoolChangeDelta(change)
+		if err != nil {
+			return err
+		}
+
+		cp := lp.Body.ConstantProduct
+		reservesRevoked := make([]map[string]string, 0, 2)
+		for _, reserve := range []struct {
+			asset  xdr.Asset
+			amount xdr.Int64
+		}{
+			{cp.Params.AssetA, -delta.ReserveA},
+			{cp.Params.AssetB, -delta.ReserveB},
+		} {
+			// Reserves of an asset issued by the trustor are burned, so not
+			// every reserve has a claimable balance
+			balanceID, err := e.operation.revokedReserveBalanceID(lp.LiquidityPoolId, reserve.asset)
+			if err != nil {
+				return err
+			}
+			hexID, err := xdr.MarshalHex(balanceID)
+			if err != nil {
+				return err
+			}
+			if _, ok := createdBalances[hexID]; !ok {
+				continue
+			}
+			strkeyID, err := claimableBalanceIDStrkey(balanceID)
+			if err != nil {
+				return err
+			}
+			reservesRevoked = append(reservesRevoked, map[string]string{
+				"asset":                       reserve.asset.StringCanonical(),
+				"amount":                      amount.String(reserve.amount),
+				"claimable_balance_id":        hexID,
+				"claimable_balance_id_strkey": strkeyID,
+			})
+		}
+		details := map[string]interface{}{
+			"liquidity_pool":   liquidityPoolDetails(lp),
+			"reserves_revoked": reservesRevoked,
+			"shares_revoked":   amount.String(-delta.TotalPoolShares),
+		}
+		e.addMuxed(source, EffectLiquidityPoolRevoked, details)
+	}
+	return nil
+}
+
+// addClaimableBalanceEntryCreatedEffects adds claimable_balance_created and one
+// claimable_balance_claimant_created per claimant of the new balance
+func (e *effectsWrapper) addClaimableBalanceEntryCreatedEffects(source *xdr.MuxedAccount, cb *xdr.ClaimableBalanceEntry) error {
+	details := map[string]interface{}{
+		"amount": amount.String(cb.Amount),
+		"asset":  cb.Asset.StringCanonical(),
+	}
+	if err := addClaimableBalanceIDDetails(details, cb.BalanceId); err != nil {
+		return err
+	}
+	setClaimableBalanceFlagDetails(details, cb.Flags())
+	e.addMuxed(source, EffectClaimableBalanceCreated, details)
+
+	// EffectClaimableBalanceClaimantCreated can be generated by
+	// `create_claimable_balance` operation but also by `liquidity_pool_withdraw`
+	// operation causing a revocation.
+	// In case of `create_claimable_balance` we use `op.Claimants` to make
+	// effects backward compatible. The reason for this is that Stellar-Core
+	// changes all `rel_before` predicated to `abs_before` when tx is included
+	// in the ledger.
+	var claimants []xdr.Claimant
+	if op, ok := e.operation.operation.Body.GetCreateClaimableBalanceOp(); ok {
+		claimants = op.Claimants
+	} else {
+		claimants = cb.Claimants
+	}
+	for _, c := range claimants {
+		cv0 := c.MustV0()
+		claimantDetails := map[string]interface{}{
+			"balance_id":        details["balance_id"],
+			"balance_id_strkey": details["balance_id_strkey"],
+			"amount":            amount.String(cb.Amount),
+			"predicate":         cv0.Predicate,
+			"asset":             cb.Asset.StringCanonical(),
+		}
+		e.addUnmuxed(&cv0.Destination, EffectClaimableBalanceClaimantCreated, claimantDetails)
+	}
+	return nil
+}
+
+func (e *effectsWrapper) addCreateClaimableBalanceEffects() error {
+	source := e.operation.SourceAccount()
+	var cb *xdr.ClaimableBalanceEntry
+	for _, change := range e.operation.changes {
+		if change.Type != xdr.LedgerEntryTypeClaimableBalance || change.Post == nil {
+			continue
+		}
+		cb = change.Post.Data.ClaimableBalance
+		if err := e.addClaimableBalanceEntryCreatedEffects(source, cb); err != nil {
+			return err
+		}
+		break
+	}
+	if cb == nil {
+		return errors.New("claimable balance entry not found")
+	}
+
+	details := map[string]interface{}{
+		"amount": amount.String(cb.Amount),
+	}
+	if err := addAssetDetails(details, cb.Asset, ""); err != nil {
+		return err
+	}
+	e.addMuxed(source, EffectAccountDebited, details)
+	return nil
+}
+
+func (e *effectsWrapper) addClaimClaimableBalanceEffects() error {
+	op := e.operation.operation.Body.MustClaimClaimableBalanceOp()
+	cBalance, err := e.operation.removedClaimableBalance(op.BalanceId)
+	if err != nil {
+		return err
+	}
+
+	details := map[string]interface{}{
+		"amount": amount.String(cBalance.Amount),
+		"asset":  cBalance.Asset.StringCanonical(),
+	}
+	if err := addClaimableBalanceIDDetails(details, op.BalanceId); err != nil {
+		return err
+	}
+	setClaimableBalanceFlagDetails(details, cBalance.Flags())
+	source := e.operation.SourceAccount()
+	e.addMuxed(source, EffectClaimableBalanceClaimed, details)
+
+	details = map[string]interface{}{
+		"amount": amount.String(cBalance.Amount),
+	}
+	if err := addAssetDetails(details, cBalance.Asset, ""); err != nil {
+		return err
+	}
+	e.addMuxed(source, EffectAccountCredited, details)
+	return nil
+}
+
+func (e *effectsWrapper) addClawbackEffects() error {
+	op := e.operation.operation.Body.MustClawbackOp()
+	details := map[string]interface{}{
+		"amount": amount.String(op.Amount),
+	}
+	if err := addAssetDetails(details, op.Asset, ""); err != nil {
+		return err
+	}
+
+	// The funds will be burned, but even with that, we generate an account
+	// credited effect for the issuer as Horizon does
+	e.addMuxed(e.operation.SourceAccount(), EffectAccountCredited, details)
+	e.addMuxed(&op.From, EffectAccountDebited, details)
+	return nil
+}
+
+func (e *effectsWrapper) addClawbackClaimableBalanceEffects() error {
+	op := e.operation.operation.Body.MustClawbackClaimableBalanceOp()
+	details := map[string]interface{}{}
+	if err := addClaimableBalanceIDDetails(details, op.BalanceId); err != nil {
+		return err
+	}
+	source := e.operation.SourceAccount()
+	e.addMuxed(source, EffectClaimableBalanceClawedBack, details)
+
+	// Generate the account credited effect (although the funds will be burned) for the asset issuer
+	cb, err := e.operation.removedClaimableBalance(op.BalanceId)
+	if err != nil {
+		return err
+	}
+	details = map[string]interface{}{"amount": amount.String(cb.Amount)}
+	if err := addAssetDetails(details, cb.Asset, ""); err != nil {
+		return err
+	}
+	e.addMuxed(source, EffectAccountCredited, details)
+	return nil
+}
+
+func (e *effectsWrapper) addAccountMergeEffects() error {
+	source := e.operation.SourceAccount()
+	dest := e.operation.operation.Body.MustDestination()
+	result := e.operation.OperationResult().MustAccountMergeResult()
+	details := map[string]interface{}{
+		"amount":     amount.String(result.MustSourceAccountBalance()),
+		"asset_type": "native",
+	}
+
+	e.addMuxed(source, EffectAccountDebited, details)
+	e.addMuxed(&dest, EffectAccountCredited, details)
+	e.addMuxed(source, EffectAccountRemoved, map[string]interface{}{})
+	return nil
+}
+
+func (e *effectsWrapper) addInflationEffects() error {
+	payouts := e.operation.OperationResult().MustInflationResult().MustPayouts()
+	for _, payout := range payouts {
+		e.addUnmuxed(&payout.Destination, EffectAccountCredited,
+			map[string]interface{}{
+				"amount":     amount.String(payout.Amount),
+				"asset_type": "native",
+			},
+		)
+	}
+	return nil
+}
+
+func (e *effectsWrapper) addManageDataEffects() error {
+	source := e.operation.SourceAccount()
+	op := e.operation.operation.Body.MustManageDataOp()
+	details := map[string]interface{}{"name": op.DataName}
+
+	for _, change := range e.operation.changes {
+		if change.Type != xdr.LedgerEntryTypeData {
+			continue
+		}
+
+		if change.Post != nil {
+			raw := change.Post.Data.MustData().DataValue
+			details["value"] = base64.StdEncoding.EncodeToString(raw)
+		}
+
+		switch {
+		case change.Pre == nil && change.Post != nil:
+			e.addMuxed(source, EffectDataCreated, details)
+		case change.Pre != nil && change.Post == nil:
+			e.addMuxed(source, EffectDataRemoved, details)
+		case change.Pre != nil && change.Post != nil:
+			e.addMuxed(source, EffectDataUpdated, details)
+		default:
+			return errors.New("invalid data entry change without pre or post state")
+		}
+		break
+	}
+
+	return nil
+}
+
+// addBumpSequenceEffects adds sequence_bumped only when the bump actually
+// moved the source account's sequence number
+func (e *effectsWrapper) addBumpSequenceEffects() error {
+	source := e.operation.SourceAccount()
+	change, ok := e.operation.accountChange(source.ToAccountId())
+	if !ok || change.Pre == nil || change.Post == nil {
+		return nil
+	}
+
+	beforeAccount := change.Pre.Data.MustAccount()
+	afterAccount := change.Post.Data.MustAccount()
+	if beforeAccount.SeqNum != afterAccount.SeqNum {
+		e.addMuxed(source, EffectSequenceBumped, map[string]interface{}{
+			"new_seq": int64(afterAccount.SeqNum),
+		})
+	}
+	return nil
+}
+
+// addLiquidityPoolDepositEffect reports the reserves deposited and shares
+// received along with the pool state after the deposit
+func (e *effectsWrapper) addLiquidityPoolDepositEffect() error {
+	op := e.operation.operation.Body.MustLiquidityPoolDepositOp()
+	lp, delta, err := e.operation.getLiquidityPoolAndProductDelta(&op.LiquidityPoolId)
+	if err != nil {
+		return err
+	}
+	details := map[string]interface{}{
+		"liquidity_pool":     liquidityPoolDetails(lp),
+		"reserves_deposited": reserveAmounts(lp, delta.ReserveA, delta.ReserveB),
+		"shares_received":    amount.String(delta.TotalPoolShares),
+	}
+	e.addMuxed(e.operation.SourceAccount(), EffectLiquidityPoolDeposited, details)
+	return nil
+}
+
+// addLiquidityPoolWithdrawEffect reports the reserves received and shares
+// redeemed along with the pool state after the withdrawal
+func (e *effectsWrapper) addLiquidityPoolWithdrawEffect() error {
+	op := e.operation.operation.Body.MustLiquidityPoolWithdrawOp()
+	lp, delta, err := e.operation.getLiquidityPoolAndProductDelta(&op.LiquidityPoolId)
+	if err != nil {
+		return err
+	}
+	details := map[string]interface{}{
+		"liquidity_pool":    liquidityPoolDetails(lp),
+		"reserves_received": reserveAmounts(lp, -delta.ReserveA, -delta.ReserveB),
+		"shares_redeemed":   amount.String(-delta.TotalPoolShares),
+	}
+	e.addMuxed(e.operation.SourceAccount(), EffectLiquidityPoolWithdrew, details)
+	return nil
+}
+
+// addInvokeHostFunctionEffects turns Stellar Asset Contract transfer, mint,
+// burn and clawback events into account_credited/account_debited effects for
+// G... holders and contract_credited/contract_debited effects for C... holders.
+func (e *effectsWrapper) addInvokeHostFunctionEffects(events []xdr.ContractEvent) error {
+	if e.operation.network == "" {
+		return errors.New("invokeHostFunction effects cannot be determined unless network passphrase is set")
+	}
+
+	for _, event := range events {
+		evt, err := contractevents.NewStellarAssetContractEvent(&event, e.operation.network)
+		if err != nil {
+			continue // irrelevant or unsupported event
+		}
+
+		details := make(map[string]interface{}, 6)
+		if err := addAssetDetails(details, evt.GetAsset(), ""); err != nil {
+			return errors.Wrap(err, "invokeHostFunction asset details had an error")
+		}
+
+		switch evt.GetType() {
+		// Transfer events debit the `from` (sender) and credit the `to` (recipient).
+		case contractevents.EventTypeTransfer:
+			transferEvent := evt.(*contractevents.TransferEvent)
+			details["contract_event_type"] = "transfer"
+			details["amount"] = amount.String128(transferEvent.Amount)
+			toDetails := make(map[string]interface{}, len(details)+1)
+			for key, val := range details {
+				toDetails[key] = val
+			}
+			e.addContractBalanceEffect(transferEvent.From, EffectAccountDebited, EffectContractDebited, details)
+			e.addContractBalanceEffect(transferEvent.To, EffectAccountCredited, EffectContractCredited, toDetails)
+
+		// Mint events imply a non-native asset, and result in a credit to the
+		// `to` recipient.
+		case contractevents.EventTypeMint:
+			mintEvent := evt.(*contractevents.MintEvent)
+			details["contract_event_type"] = "mint"
+			details["amount"] = amount.String128(mintEvent.Amount)
+			e.addContractBalanceEffect(mintEvent.To, EffectAccountCredited, EffectContractCredited, details)
+
+		// Clawback events result in a debit to the `from` address, but act
+		// like a burn to the recipient, so these are functionally equivalent
+		case contractevents.EventTypeClawback:
+			cbEvent := evt.(*contractevents.ClawbackEvent)
+			details["contract_event_type"] = "clawback"
+			details["amount"] = amount.String128(cbEvent.Amount)
+			e.addContractBalanceEffect(cbEvent.From, EffectAccountDebited, EffectContractDebited, details)
+
+		case contractevents.EventTypeBurn:
+			burnEvent := evt.(*contractevents.BurnEvent)
+			details["contract_event_type"] = "burn"
+			details["amount"] = amount.String128(burnEvent.Amount)
+			e.addContractBalanceEffect(burnEvent.From, EffectAccountDebited, EffectContractDebited, details)
+		}
+	}
+
+	return nil
+}
+
+// addContractBalanceEffect adds accountEffect when the holder is an account and
+// contractEffect, attributed to the operation source, when it is a contract
+func (e *effectsWrapper) addContractBalanceEffect(holder string, accountEffect, contractEffect EffectType, details map[string]interface{}) {
+	if strkey.IsValidEd25519PublicKey(holder) {
+		e.add(holder, null.String{}, accountEffect, details)
+		return
+	}
+	details["contract"] = holder
+	e.addMuxed(e.operation.SourceAccount(), contractEffect, details)
+}
+
+// addExtendFootprintTtlEffect reports the new TTL and the read-only footprint
+// entries whose TTL the operation changed
+func (e *effectsWrapper) addExtendFootprintTtlEffect() error {
+	op := e.operation.operation.Body.MustExtendFootprintTtlOp()
+	sorobanData, ok := e.operation.transaction.GetSorobanData()
+	if !ok {
+		return errors.New("extend footprint ttl operation without soroban transaction data")
+	}
+
+	entries, err := e.operation.footprintTTLChanges(sorobanData.Resources.Footprint.ReadOnly)
+	if err != nil {
+		return err
+	}
+	details := map[string]interface{}{
+		"entries":   entries,
+		"extend_to": uint32(op.ExtendTo),
+	}
+	e.addMuxed(e.operation.SourceAccount(), EffectExtendFootprintTtl, details)
+	return nil
+}
+
+// addRestoreFootprintEffect reports the read-write footprint entries the
+// operation restored from the archive
+func (e *effectsWrapper) addRestoreFootprintEffect() error {
+	sorobanData, ok := e.operation.transaction.GetSorobanData()
+	if !ok {
+		return errors.New("restore footprint operation without soroban transaction data")
+	}
+
+	entries, err := e.operation.footprintTTLChanges(sorobanData.Resources.Footprint.ReadWrite)
+	if err != nil {
+		return err
+	}
+	details := map[string]interface{}{
+		"entries": entries,
+	}
+	e.addMuxed(e.operation.SourceAccount(), EffectRestoreFootprint, details)
+	return nil
+}
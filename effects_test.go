@@ -0,0 +1,1114 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/contractevents"
+	"github.com/stellar/go/xdr"
+)
+
+const (
+	sourceAddress     = "GBWQTFBOGLD3XPPF474QCU45ER4O6TWJ7YVXQ7VYX5IX4G6JIJ2U6ANX"
+	destAddress       = "GD74SEO4VMTIHHALWE7GDBFA52NYTCVX54PQBLOSH44QFHFFLMGITB2I"
+	issuerAddress     = "GCWL3KFOHGUESB4H7R74LLMSGSIOKL3JVWKLJ4BD2ZPCX7PCULRSDN3E"
+	sellerAddress     = "GBX5MO4PUJX6ICOQKQCVM6KREHP7WRUGGWF6THW6RXIPKTG37M6XIYSM"
+	trustorAddress    = "GCLUZ6WNMDXV2Q6KVADW6OJBQXQN53SSN6BS2IFNI74NUJRQ4PHBX3LL"
+	sponsorAddress    = "GAQ5P4HQT25MWCHQ4VWNBNJZZHVB7MTBRZ5QERMJWD5BSON3W2MG67UJ"
+	newSponsorAddress = "GCEECCSYCHSWOPK7KZOPRSSZDXVRI4BWZJEBJJ2DIL7WDSUTJ6CYF6YY"
+
+	testLedgerSequence = 10
+	testSeqNum         = 4242
+	testPoolFee        = 30
+)
+
+var (
+	nativeAsset = xdr.MustNewNativeAsset()
+	usdAsset    = xdr.MustNewCreditAsset("USD", issuerAddress)
+	eurAsset    = xdr.MustNewCreditAsset("EUR", issuerAddress)
+	testTxHash  = xdr.Hash{0xaa}
+)
+
+// wantEffect is an expected effect. Only the listed details are compared, and
+// a nil detail must be absent.
+type wantEffect struct {
+	typ          EffectType
+	address      string
+	addressMuxed string
+	details      map[string]interface{}
+}
+
+// effectsTestCase is a successful transaction with a single operation and the
+// effects expected from it
+type effectsTestCase struct {
+	name    string
+	source  *xdr.MuxedAccount // operation source, the transaction source when nil
+	op      xdr.OperationBody
+	result  xdr.OperationResultTr
+	changes xdr.LedgerEntryChanges
+	soroban *xdr.SorobanTransactionData
+	events  []xdr.ContractEvent
+	want    []wantEffect
+}
+
+func TestOperationEffects(t *testing.T) {
+	muxedDest := mustMuxed(destAddress, 7)
+	issuer := xdr.MustMuxedAddress(issuerAddress)
+	dest := xdr.MustMuxedAddress(destAddress)
+	sponsor := xdr.MustMuxedAddress(sponsorAddress)
+
+	for _, tc := range []effectsTestCase{
+		{
+			name: "payment to a muxed destination",
+			op: xdr.OperationBody{
+				Type:      xdr.OperationTypePayment,
+				PaymentOp: &xdr.PaymentOp{Destination: muxedDest, Asset: usdAsset, Amount: 100000000},
+			},
+			result: xdr.OperationResultTr{
+				Type:          xdr.OperationTypePayment,
+				PaymentResult: &xdr.PaymentResult{Code: xdr.PaymentResultCodePaymentSuccess},
+			},
+			want: []wantEffect{
+				{typ: EffectAccountCredited, address: destAddress, addressMuxed: muxedDest.Address(), details: map[string]interface{}{
+					"amount": "10.0000000", "asset_code": "USD", "asset_issuer": issuerAddress, "address_muxed_id": 7,
+				}},
+				{typ: EffectAccountDebited, address: sourceAddress, details: map[string]interface{}{
+					"amount": "10.0000000", "asset_code": "USD", "address_muxed_id": nil,
+				}},
+			},
+		},
+		pathPaymentStrictReceiveCase(),
+		pathPaymentStrictSendCase(),
+		{
+			name: "manage sell offer created after crossing an offer",
+			op: xdr.OperationBody{
+				Type: xdr.OperationTypeManageSellOffer,
+				ManageSellOfferOp: &xdr.ManageSellOfferOp{
+					Selling: nativeAsset, Buying: usdAsset, Amount: 100000000, Price: xdr.Price{N: 1, D: 2},
+				},
+			},
+			result: xdr.OperationResultTr{
+				Type: xdr.OperationTypeManageSellOffer,
+				ManageSellOfferResult: &xdr.ManageSellOfferResult{
+					Code: xdr.ManageSellOfferResultCodeManageSellOfferSuccess,
+					Success: &xdr.ManageOfferSuccessResult{
+						OffersClaimed: []xdr.ClaimAtom{orderBookClaim(sellerAddress, 9, 10000000, 20000000)},
+						Offer: xdr.ManageOfferSuccessResultOffer{
+							Effect: xdr.ManageOfferEffectManageOfferCreated,
+							Offer:  offerEntry(sourceAddress, 11, nativeAsset, usdAsset, 80000000),
+						},
+					},
+				},
+			},
+			changes: joinChanges(
+				entryUpdated(offerData(sellerAddress, 9, usdAsset, nativeAsset, 50000000), offerData(sellerAddress, 9, usdAsset, nativeAsset, 40000000)),
+				entryCreated(offerData(sourceAddress, 11, nativeAsset, usdAsset, 80000000)),
+			),
+			want: []wantEffect{
+				{typ: EffectTrade, address: sourceAddress, details: map[string]interface{}{"offer_id": 9, "seller": sellerAddress}},
+				{typ: EffectTrade, address: sellerAddress, details: map[string]interface{}{"offer_id": 9, "seller": sourceAddress}},
+				{typ: EffectOfferUpdated, address: sellerAddress, details: map[string]interface{}{"offer_id": 9, "amount": "4.0000000"}},
+				{typ: EffectOfferCreated, address: sourceAddress, details: map[string]interface{}{
+					"offer_id": 11, "amount": "8.0000000", "selling_asset_type": "native", "buying_asset_code": "USD",
+				}},
+			},
+		},
+		{
+			name: "manage sell offer updated",
+			op: xdr.OperationBody{
+				Type: xdr.OperationTypeManageSellOffer,
+				ManageSellOfferOp: &xdr.ManageSellOfferOp{
+					Selling: nativeAsset, Buying: usdAsset, Amount: 60000000, Price: xdr.Price{N: 1, D: 2}, OfferId: 11,
+				},
+			},
+			result: xdr.OperationResultTr{
+				Type: xdr.OperationTypeManageSellOffer,
+				ManageSellOfferResult: &xdr.ManageSellOfferResult{
+					Code: xdr.ManageSellOfferResultCodeManageSellOfferSuccess,
+					Success: &xdr.ManageOfferSuccessResult{
+						Offer: xdr.ManageOfferSuccessResultOffer{
+							Effect: xdr.ManageOfferEffectManageOfferUpdated,
+							Offer:  offerEntry(sourceAddress, 11, nativeAsset, usdAsset, 60000000),
+						},
+					},
+				},
+			},
+			changes: entryUpdated(offerData(sourceAddress, 11, nativeAsset, usdAsset, 80000000), offerData(sourceAddress, 11, nativeAsset, usdAsset, 60000000)),
+			want: []wantEffect{
+				{typ: EffectOfferUpdated, address: sourceAddress, details: map[string]interface{}{"offer_id": 11, "amount": "6.0000000"}},
+			},
+		},
+		{
+			name: "manage buy offer deleted",
+			op: xdr.OperationBody{
+				Type: xdr.OperationTypeManageBuyOffer,
+				ManageBuyOfferOp: &xdr.ManageBuyOfferOp{
+					Selling: nativeAsset, Buying: usdAsset, Price: xdr.Price{N: 2, D: 1}, OfferId: 11,
+				},
+			},
+			result: xdr.OperationResultTr{
+				Type: xdr.OperationTypeManageBuyOffer,
+				ManageBuyOfferResult: &xdr.ManageBuyOfferResult{
+					Code: xdr.ManageBuyOfferResultCodeManageBuyOfferSuccess,
+					Success: &xdr.ManageOfferSuccessResult{
+						Offer: xdr.ManageOfferSuccessResultOffer{Effect: xdr.ManageOfferEffectManageOfferDeleted},
+					},
+				},
+			},
+			changes: entryRemoved(offerData(sourceAddress, 11, nativeAsset, usdAsset, 60000000)),
+			want: []wantEffect{
+				{typ: EffectOfferRemoved, address: sourceAddress, details: map[string]interface{}{"offer_id": 11, "amount": "6.0000000"}},
+			},
+		},
+		setOptionsSignersCase(),
+		changeTrustPoolShareCase(),
+		revokeAuthorizationCase(),
+		{
+			name: "claimable balance created",
+			op: xdr.OperationBody{
+				Type: xdr.OperationTypeCreateClaimableBalance,
+				CreateClaimableBalanceOp: &xdr.CreateClaimableBalanceOp{
+					Asset: usdAsset, Amount: 50000000, Claimants: []xdr.Claimant{unconditionalClaimant(destAddress)},
+				},
+			},
+			result: xdr.OperationResultTr{
+				Type: xdr.OperationTypeCreateClaimableBalance,
+				CreateClaimableBalanceResult: &xdr.CreateClaimableBalanceResult{
+					Code:      xdr.CreateClaimableBalanceResultCodeCreateClaimableBalanceSuccess,
+					BalanceId: &sep23BalanceID,
+				},
+			},
+			changes: entryCreated(claimableBalanceData(sep23BalanceID, usdAsset, 50000000, destAddress)),
+			want: []wantEffect{
+				{typ: EffectClaimableBalanceCreated, address: sourceAddress, details: map[string]interface{}{
+					"balance_id": sep23BalanceIDHex, "balance_id_strkey": sep23BalanceIDStrkey,
+					"amount": "5.0000000", "asset": "USD:" + issuerAddress,
+				}},
+				{typ: EffectClaimableBalanceClaimantCreated, address: destAddress, details: map[string]interface{}{
+					"balance_id_strkey": sep23BalanceIDStrkey, "amount": "5.0000000",
+				}},
+				{typ: EffectAccountDebited, address: sourceAddress, details: map[string]interface{}{"amount": "5.0000000", "asset_code": "USD"}},
+			},
+		},
+		{
+			name:   "claimable balance claimed",
+			source: &dest,
+			op: xdr.OperationBody{
+				Type:                    xdr.OperationTypeClaimClaimableBalance,
+				ClaimClaimableBalanceOp: &xdr.ClaimClaimableBalanceOp{BalanceId: sep23BalanceID},
+			},
+			result: xdr.OperationResultTr{
+				Type: xdr.OperationTypeClaimClaimableBalance,
+				ClaimClaimableBalanceResult: &xdr.ClaimClaimableBalanceResult{
+					Code: xdr.ClaimClaimableBalanceResultCodeClaimClaimableBalanceSuccess,
+				},
+			},
+			changes: entryRemoved(claimableBalanceData(sep23BalanceID, usdAsset, 50000000, destAddress)),
+			want: []wantEffect{
+				{typ: EffectClaimableBalanceClaimed, address: destAddress, details: map[string]interface{}{
+					"balance_id": sep23BalanceIDHex, "balance_id_strkey": sep23BalanceIDStrkey, "amount": "5.0000000",
+				}},
+				{typ: EffectAccountCredited, address: destAddress, details: map[string]interface{}{"amount": "5.0000000", "asset_code": "USD"}},
+			},
+		},
+		{
+			name:   "claimable balance clawed back",
+			source: &issuer,
+			op: xdr.OperationBody{
+				Type:                       xdr.OperationTypeClawbackClaimableBalance,
+				ClawbackClaimableBalanceOp: &xdr.ClawbackClaimableBalanceOp{BalanceId: sep23BalanceID},
+			},
+			result: xdr.OperationResultTr{
+				Type: xdr.OperationTypeClawbackClaimableBalance,
+				ClawbackClaimableBalanceResult: &xdr.ClawbackClaimableBalanceResult{
+					Code: xdr.ClawbackClaimableBalanceResultCodeClawbackClaimableBalanceSuccess,
+				},
+			},
+			changes: entryRemoved(claimableBalanceData(sep23BalanceID, usdAsset, 50000000, destAddress)),
+			want: []wantEffect{
+				{typ: EffectClaimableBalanceClawedBack, address: issuerAddress, details: map[string]interface{}{
+					"balance_id": sep23BalanceIDHex, "balance_id_strkey": sep23BalanceIDStrkey,
+				}},
+				{typ: EffectAccountCredited, address: issuerAddress, details: map[string]interface{}{"amount": "5.0000000", "asset_code": "USD"}},
+			},
+		},
+		{
+			name:   "trustline sponsorship transferred",
+			source: &sponsor,
+			op: xdr.OperationBody{
+				Type: xdr.OperationTypeRevokeSponsorship,
+				RevokeSponsorshipOp: &xdr.RevokeSponsorshipOp{
+					Type: xdr.RevokeSponsorshipTypeRevokeSponsorshipLedgerEntry,
+					LedgerKey: &xdr.LedgerKey{Type: xdr.LedgerEntryTypeTrustline, TrustLine: &xdr.LedgerKeyTrustLine{
+						AccountId: xdr.MustAddress(trustorAddress), Asset: usdAsset.ToTrustLineAsset(),
+					}},
+				},
+			},
+			result: xdr.OperationResultTr{
+				Type: xdr.OperationTypeRevokeSponsorship,
+				RevokeSponsorshipResult: &xdr.RevokeSponsorshipResult{
+					Code: xdr.RevokeSponsorshipResultCodeRevokeSponsorshipSuccess,
+				},
+			},
+			changes: xdr.LedgerEntryChanges{
+				{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: sponsoredEntry(trustLineData(trustorAddress, usdAsset.ToTrustLineAsset(), 0, 1), sponsorAddress)},
+				{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: sponsoredEntry(trustLineData(trustorAddress, usdAsset.ToTrustLineAsset(), 0, 1), newSponsorAddress)},
+			},
+			want: []wantEffect{
+				{typ: EffectTrustlineSponsorshipUpdated, address: trustorAddress, details: map[string]interface{}{
+					"asset": "USD:" + issuerAddress, "former_sponsor": sponsorAddress, "new_sponsor": newSponsorAddress,
+				}},
+			},
+		},
+		stellarAssetContractCase(),
+		extendFootprintTTLCase(),
+		restoreFootprintCase(),
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			checkEffects(t, testEffects(t, testTransaction(tc)), tc.want)
+		})
+	}
+}
+
+func TestFeeBumpEffectsTagging(t *testing.T) {
+	tc := effectsTestCase{
+		op: xdr.OperationBody{
+			Type:      xdr.OperationTypePayment,
+			PaymentOp: &xdr.PaymentOp{Destination: xdr.MustMuxedAddress(destAddress), Asset: nativeAsset, Amount: 10000000},
+		},
+		result: xdr.OperationResultTr{
+			Type:          xdr.OperationTypePayment,
+			PaymentResult: &xdr.PaymentResult{Code: xdr.PaymentResultCodePaymentSuccess},
+		},
+	}
+
+	for _, effect := range testEffects(t, testTransaction(tc)) {
+		if effect.TransactionHash != testTxHash.HexString() {
+			t.Errorf("transaction_hash: got %s, want %s", effect.TransactionHash, testTxHash.HexString())
+		}
+		if effect.InnerTransactionHash.Valid || effect.FeeAccount.Valid || effect.FeeAccountMuxed.Valid {
+			t.Errorf("non fee-bump effect has fee-bump fields: %+v", effect)
+		}
+	}
+
+	feeSource := mustMuxed(newSponsorAddress, 9)
+	innerHash := xdr.Hash{0xbb}
+	effects := testEffects(t, wrapInFeeBump(testTransaction(tc), feeSource, innerHash))
+	if len(effects) != 2 {
+		t.Fatalf("got %d effects, want 2", len(effects))
+	}
+	operationID := effects[0].OperationID
+	for i, effect := range effects {
+		if effect.TransactionHash != testTxHash.HexString() {
+			t.Errorf("effect %d transaction_hash: got %s, want the outer hash %s", i, effect.TransactionHash, testTxHash.HexString())
+		}
+		if effect.InnerTransactionHash.String != innerHash.HexString() {
+			t.Errorf("effect %d inner_transaction_hash: got %v, want %s", i, effect.InnerTransactionHash, innerHash.HexString())
+		}
+		if effect.FeeAccount.String != newSponsorAddress {
+			t.Errorf("effect %d fee_account: got %v, want %s", i, effect.FeeAccount, newSponsorAddress)
+		}
+		if effect.FeeAccountMuxed.String != feeSource.Address() {
+			t.Errorf("effect %d fee_account_muxed: got %v, want %s", i, effect.FeeAccountMuxed, feeSource.Address())
+		}
+		if want := fmt.Sprintf("%d-%d", operationID, i); effect.EffectId != want {
+			t.Errorf("effect %d id: got %s, want %s", i, effect.EffectId, want)
+		}
+		if want := fmt.Sprintf("%d-%d", operationID, i+1); effect.PagingToken != want {
+			t.Errorf("effect %d paging_token: got %s, want %s", i, effect.PagingToken, want)
+		}
+	}
+}
+
+// pathPaymentStrictReceiveCase crosses an order book offer, a liquidity pool
+// and a second offer. The first offer is filled and the second one partially.
+func pathPaymentStrictReceiveCase() effectsTestCase {
+	poolID := testPoolID(nativeAsset, usdAsset)
+	claims := []xdr.ClaimAtom{
+		orderBookClaim(sellerAddress, 1, 20000000, 40000000),
+		poolClaim(poolID, 30000000, 60000000),
+		orderBookClaim(sellerAddress, 2, 50000000, 100000000),
+	}
+
+	return effectsTestCase{
+		name: "path payment strict receive through offers and a pool",
+		op: xdr.OperationBody{
+			Type: xdr.OperationTypePathPaymentStrictReceive,
+			PathPaymentStrictReceiveOp: &xdr.PathPaymentStrictReceiveOp{
+				SendAsset: nativeAsset, SendMax: 250000000,
+				Destination: xdr.MustMuxedAddress(destAddress), DestAsset: usdAsset, DestAmount: 100000000,
+			},
+		},
+		result: xdr.OperationResultTr{
+			Type: xdr.OperationTypePathPaymentStrictReceive,
+			PathPaymentStrictReceiveResult: &xdr.PathPaymentStrictReceiveResult{
+				Code: xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveSuccess,
+				Success: &xdr.PathPaymentStrictReceiveResultSuccess{
+					Offers: claims,
+					Last:   xdr.SimplePaymentResult{Destination: xdr.MustAddress(destAddress), Asset: usdAsset, Amount: 100000000},
+				},
+			},
+		},
+		changes: joinChanges(
+			entryRemoved(offerData(sellerAddress, 1, usdAsset, nativeAsset, 20000000)),
+			entryUpdated(poolData(nativeAsset, usdAsset, 10000000000, 5000000000, 7000000000), poolData(nativeAsset, usdAsset, 10060000000, 4970000000, 7000000000)),
+			entryUpdated(offerData(sellerAddress, 2, usdAsset, nativeAsset, 80000000), offerData(sellerAddress, 2, usdAsset, nativeAsset, 30000000)),
+		),
+		want: []wantEffect{
+			{typ: EffectAccountCredited, address: destAddress, details: map[string]interface{}{"amount": "10.0000000", "asset_code": "USD"}},
+			{typ: EffectAccountDebited, address: sourceAddress, details: map[string]interface{}{"amount": "20.0000000", "asset_type": "native"}},
+			{typ: EffectTrade, address: sourceAddress, details: map[string]interface{}{
+				"offer_id": 1, "seller": sellerAddress, "bought_amount": "2.0000000", "sold_amount": "4.0000000",
+			}},
+			{typ: EffectTrade, address: sellerAddress, details: map[string]interface{}{"offer_id": 1, "seller": sourceAddress}},
+			{typ: EffectLiquidityPoolTrade, address: sourceAddress, details: map[string]interface{}{
+				"sold":   map[string]string{"asset": "USD:" + issuerAddress, "amount": "3.0000000"},
+				"bought": map[string]string{"asset": "native", "amount": "6.0000000"},
+			}},
+			{typ: EffectTrade, address: sourceAddress, details: map[string]interface{}{"offer_id": 2, "bought_amount": "5.0000000"}},
+			{typ: EffectTrade, address: sellerAddress, details: map[string]interface{}{"offer_id": 2}},
+			{typ: EffectOfferRemoved, address: sellerAddress, details: map[string]interface{}{"offer_id": 1, "amount": "2.0000000"}},
+			{typ: EffectOfferUpdated, address: sellerAddress, details: map[string]interface{}{"offer_id": 2, "amount": "3.0000000"}},
+		},
+	}
+}
+
+// pathPaymentStrictSendCase crosses a liquidity pool and then fills an order
+// book offer
+func pathPaymentStrictSendCase() effectsTestCase {
+	poolID := testPoolID(nativeAsset, usdAsset)
+	claims := []xdr.ClaimAtom{
+		poolClaim(poolID, 30000000, 60000000),
+		orderBookClaim(sellerAddress, 1, 20000000, 40000000),
+	}
+
+	return effectsTestCase{
+		name: "path payment strict send through a pool and an offer",
+		op: xdr.OperationBody{
+			Type: xdr.OperationTypePathPaymentStrictSend,
+			PathPaymentStrictSendOp: &xdr.PathPaymentStrictSendOp{
+				SendAsset: nativeAsset, SendAmount: 100000000,
+				Destination: xdr.MustMuxedAddress(destAddress), DestAsset: usdAsset, DestMin: 40000000,
+			},
+		},
+		result: xdr.OperationResultTr{
+			Type: xdr.OperationTypePathPaymentStrictSend,
+			PathPaymentStrictSendResult: &xdr.PathPaymentStrictSendResult{
+				Code: xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendSuccess,
+				Success: &xdr.PathPaymentStrictSendResultSuccess{
+					Offers: claims,
+					Last:   xdr.SimplePaymentResult{Destination: xdr.MustAddress(destAddress), Asset: usdAsset, Amount: 50000000},
+				},
+			},
+		},
+		changes: joinChanges(
+			entryUpdated(poolData(nativeAsset, usdAsset, 10000000000, 5000000000, 7000000000), poolData(nativeAsset, usdAsset, 10060000000, 4970000000, 7000000000)),
+			entryRemoved(offerData(sellerAddress, 1, usdAsset, nativeAsset, 20000000)),
+		),
+		want: []wantEffect{
+			{typ: EffectAccountCredited, address: destAddress, details: map[string]interface{}{"amount": "5.0000000", "asset_code": "USD"}},
+			{typ: EffectAccountDebited, address: sourceAddress, details: map[string]interface{}{"amount": "10.0000000", "asset_type": "native"}},
+			{typ: EffectLiquidityPoolTrade, address: sourceAddress},
+			{typ: EffectTrade, address: sourceAddress, details: map[string]interface{}{"offer_id": 1}},
+			{typ: EffectTrade, address: sellerAddress, details: map[string]interface{}{"offer_id": 1}},
+			{typ: EffectOfferRemoved, address: sellerAddress, details: map[string]interface{}{"offer_id": 1}},
+		},
+	}
+}
+
+// setOptionsSignersCase updates an ed25519 signer, removes a hash-x signer and
+// adds a signed payload signer
+func setOptionsSignersCase() effectsTestCase {
+	ed25519Signer := xdr.MustSigner(destAddress)
+	hashXSigner := xdr.SignerKey{Type: xdr.SignerKeyTypeSignerKeyTypeHashX, HashX: &xdr.Uint256{1, 2, 3}}
+	payloadSigner := xdr.SignerKey{
+		Type: xdr.SignerKeyTypeSignerKeyTypeEd25519SignedPayload,
+		Ed25519SignedPayload: &xdr.SignerKeyEd25519SignedPayload{
+			Ed25519: xdr.MustSigner(sellerAddress).MustEd25519(),
+			Payload: []byte{1, 2, 3, 4},
+		},
+	}
+
+	return effectsTestCase{
+		name: "set options signer changes",
+		op: xdr.OperationBody{
+			Type:         xdr.OperationTypeSetOptions,
+			SetOptionsOp: &xdr.SetOptionsOp{Signer: &xdr.Signer{Key: payloadSigner, Weight: 1}},
+		},
+		result: xdr.OperationResultTr{
+			Type:             xdr.OperationTypeSetOptions,
+			SetOptionsResult: &xdr.SetOptionsResult{Code: xdr.SetOptionsResultCodeSetOptionsSuccess},
+		},
+		changes: entryUpdated(
+			accountData(sourceAddress, "", []xdr.Signer{{Key: ed25519Signer, Weight: 1}, {Key: hashXSigner, Weight: 2}}),
+			accountData(sourceAddress, "example.com", []xdr.Signer{{Key: ed25519Signer, Weight: 3}, {Key: payloadSigner, Weight: 1}}),
+		),
+		want: []wantEffect{
+			{typ: EffectAccountHomeDomainUpdated, address: sourceAddress, details: map[string]interface{}{"home_domain": "example.com"}},
+			{typ: EffectSignerUpdated, address: sourceAddress, details: map[string]interface{}{"public_key": destAddress, "weight": 3}},
+			{typ: EffectSignerRemoved, address: sourceAddress, details: map[string]interface{}{"public_key": hashXSigner.Address()}},
+			{typ: EffectSignerCreated, address: sourceAddress, details: map[string]interface{}{"public_key": payloadSigner.Address(), "weight": 1}},
+		},
+	}
+}
+
+// changeTrustPoolShareCase creates a pool-share trustline along with its pool
+func changeTrustPoolShareCase() effectsTestCase {
+	poolID := testPoolID(nativeAsset, usdAsset)
+	poolShare := xdr.TrustLineAsset{Type: xdr.AssetTypeAssetTypePoolShare, LiquidityPoolId: &poolID}
+
+	return effectsTestCase{
+		name: "change trust on a pool share",
+		op: xdr.OperationBody{
+			Type: xdr.OperationTypeChangeTrust,
+			ChangeTrustOp: &xdr.ChangeTrustOp{
+				Line: xdr.ChangeTrustAsset{
+					Type: xdr.AssetTypeAssetTypePoolShare,
+					LiquidityPool: &xdr.LiquidityPoolParameters{
+						Type: xdr.LiquidityPoolTypeLiquidityPoolConstantProduct,
+						ConstantProduct: &xdr.LiquidityPoolConstantProductParameters{
+							AssetA: nativeAsset, AssetB: usdAsset, Fee: testPoolFee,
+						},
+					},
+				},
+				Limit: 10000000000,
+			},
+		},
+		result: xdr.OperationResultTr{
+			Type:              xdr.OperationTypeChangeTrust,
+			ChangeTrustResult: &xdr.ChangeTrustResult{Code: xdr.ChangeTrustResultCodeChangeTrustSuccess},
+		},
+		changes: joinChanges(
+			entryCreated(trustLineData(sourceAddress, poolShare, 0, 0)),
+			entryCreated(poolData(nativeAsset, usdAsset, 0, 0, 0)),
+		),
+		want: []wantEffect{
+			{typ: EffectTrustlineCreated, address: sourceAddress, details: map[string]interface{}{
+				"asset_type": "liquidity_pool_shares", "liquidity_pool_id": poolIDToString(poolID), "limit": "1000.0000000",
+			}},
+			{typ: EffectLiquidityPoolCreated, address: sourceAddress},
+		},
+	}
+}
+
+// revokeAuthorizationCase revokes a trustor holding shares of two pools that
+// both contain the revoked asset, so every reserve of both pools is put in a
+// claimable balance
+func revokeAuthorizationCase() effectsTestCase {
+	issuer := xdr.MustMuxedAddress(issuerAddress)
+	pools := []struct {
+		assetA, assetB     xdr.Asset
+		reserveA, reserveB xdr.Int64
+		shares             xdr.Int64
+	}{
+		{nativeAsset, usdAsset, 100000000, 50000000, 70000000},
+		{eurAsset, usdAsset, 40000000, 80000000, 50000000},
+	}
+
+	type revokedBalance struct {
+		id     xdr.ClaimableBalanceId
+		asset  xdr.Asset
+		amount xdr.Int64
+	}
+	var (
+		changes  = entryUpdated(trustLineData(trustorAddress, usdAsset.ToTrustLineAsset(), 0, 1), trustLineData(trustorAddress, usdAsset.ToTrustLineAsset(), 0, 0))
+		balances []revokedBalance
+		revoked  []wantEffect
+	)
+	for _, pool := range pools {
+		poolID := testPoolID(pool.assetA, pool.assetB)
+		// The trustor owns a tenth of each pool
+		amountA, amountB, shares := pool.reserveA/10, pool.reserveB/10, pool.shares/10
+		changes = joinChanges(changes,
+			entryRemoved(trustLineData(trustorAddress, xdr.TrustLineAsset{Type: xdr.AssetTypeAssetTypePoolShare, LiquidityPoolId: &poolID}, shares, 0)),
+			entryUpdated(
+				poolData(pool.assetA, pool.assetB, pool.reserveA, pool.reserveB, pool.shares),
+				poolData(pool.assetA, pool.assetB, pool.reserveA-amountA, pool.reserveB-amountB, pool.shares-shares),
+			),
+		)
+
+		reserves := []map[string]string{}
+		for _, reserve := range []struct {
+			asset  xdr.Asset
+			amount xdr.Int64
+		}{{pool.assetA, amountA}, {pool.assetB, amountB}} {
+			id := testRevokeBalanceID(poolID, reserve.asset)
+			changes = joinChanges(changes, entryCreated(claimableBalanceData(id, reserve.asset, reserve.amount, trustorAddress)))
+			balances = append(balances, revokedBalance{id, reserve.asset, reserve.amount})
+			hexID, strkeyID := balanceIDStrings(id)
+			reserves = append(reserves, map[string]string{
+				"asset":                       reserve.asset.StringCanonical(),
+				"amount":                      amount.String(reserve.amount),
+				"claimable_balance_id":        hexID,
+				"claimable_balance_id_strkey": strkeyID,
+			})
+		}
+		revoked = append(revoked, wantEffect{typ: EffectLiquidityPoolRevoked, address: issuerAddress, details: map[string]interface{}{
+			"reserves_revoked": reserves,
+			"shares_revoked":   amount.String(shares),
+		}})
+	}
+
+	// Balances are ordered by asset, then by balance ID, and revoked pools by
+	// pool ID
+	sortedBalances := []revokedBalance{balances[0], balances[2]}
+	if usd1, usd2 := balances[1], balances[3]; bytes.Compare(usd1.id.V0[:], usd2.id.V0[:]) < 0 {
+		sortedBalances = append(sortedBalances, usd1, usd2)
+	} else {
+		sortedBalances = append(sortedBalances, usd2, usd1)
+	}
+	if poolIDToString(testPoolID(eurAsset, usdAsset)) < poolIDToString(testPoolID(nativeAsset, usdAsset)) {
+		revoked[0], revoked[1] = revoked[1], revoked[0]
+	}
+
+	want := []wantEffect{
+		{typ: EffectTrustlineFlagsUpdated, address: issuerAddress, details: map[string]interface{}{
+			"trustor": trustorAddress, "asset_code": "USD", "authorized_flag": false,
+		}},
+	}
+	for _, balance := range sortedBalances {
+		hexID, strkeyID := balanceIDStrings(balance.id)
+		want = append(want,
+			wantEffect{typ: EffectClaimableBalanceCreated, address: issuerAddress, details: map[string]interface{}{
+				"balance_id": hexID, "balance_id_strkey": strkeyID, "asset": balance.asset.StringCanonical(),
+			}},
+			wantEffect{typ: EffectClaimableBalanceClaimantCreated, address: trustorAddress, details: map[string]interface{}{
+				"balance_id": hexID,
+			}},
+		)
+	}
+	want = append(want, revoked...)
+
+	return effectsTestCase{
+		name:   "set trustline flags revoking two pools",
+		source: &issuer,
+		op: xdr.OperationBody{
+			Type: xdr.OperationTypeSetTrustLineFlags,
+			SetTrustLineFlagsOp: &xdr.SetTrustLineFlagsOp{
+				Trustor:    xdr.MustAddress(trustorAddress),
+				Asset:      usdAsset,
+				ClearFlags: xdr.Uint32(xdr.TrustLineFlagsAuthorizedFlag),
+			},
+		},
+		result: xdr.OperationResultTr{
+			Type: xdr.OperationTypeSetTrustLineFlags,
+			SetTrustLineFlagsResult: &xdr.SetTrustLineFlagsResult{
+				Code: xdr.SetTrustLineFlagsResultCodeSetTrustLineFlagsSuccess,
+			},
+		},
+		changes: changes,
+		want:    want,
+	}
+}
+
+// stellarAssetContractCase emits a transfer from an account to a contract, a
+// mint to an account, a burn from the contract and a clawback from an account
+func stellarAssetContractCase() effectsTestCase {
+	contractHash := xdr.Hash{0xcc}
+	contract := strkey.MustEncode(strkey.VersionByteContract, contractHash[:])
+	event := func(eventType contractevents.EventType, from, to string, amount int64) xdr.ContractEvent {
+		return contractevents.GenerateEvent(eventType, from, to, issuerAddress, usdAsset, big.NewInt(amount), network.TestNetworkPassphrase)
+	}
+
+	return effectsTestCase{
+		name: "stellar asset contract events",
+		op: xdr.OperationBody{
+			Type:                 xdr.OperationTypeInvokeHostFunction,
+			InvokeHostFunctionOp: &xdr.InvokeHostFunctionOp{},
+		},
+		result: xdr.OperationResultTr{
+			Type: xdr.OperationTypeInvokeHostFunction,
+			InvokeHostFunctionResult: &xdr.InvokeHostFunctionResult{
+				Code:    xdr.InvokeHostFunctionResultCodeInvokeHostFunctionSuccess,
+				Success: &xdr.Hash{},
+			},
+		},
+		events: []xdr.ContractEvent{
+			event(contractevents.EventTypeTransfer, sourceAddress, contract, 30000000),
+			event(contractevents.EventTypeMint, "", destAddress, 40000000),
+			event(contractevents.EventTypeBurn, contract, "", 10000000),
+			event(contractevents.EventTypeClawback, destAddress, "", 20000000),
+		},
+		want: []wantEffect{
+			{typ: EffectAccountDebited, address: sourceAddress, details: map[string]interface{}{
+				"contract_event_type": "transfer", "amount": "3.0000000", "asset_code": "USD", "contract": nil,
+			}},
+			{typ: EffectContractCredited, address: sourceAddress, details: map[string]interface{}{
+				"contract_event_type": "transfer", "amount": "3.0000000", "contract": contract,
+			}},
+			{typ: EffectAccountCredited, address: destAddress, details: map[string]interface{}{
+				"contract_event_type": "mint", "amount": "4.0000000",
+			}},
+			{typ: EffectContractDebited, address: sourceAddress, details: map[string]interface{}{
+				"contract_event_type": "burn", "amount": "1.0000000", "contract": contract,
+			}},
+			{typ: EffectAccountDebited, address: destAddress, details: map[string]interface{}{
+				"contract_event_type": "clawback", "amount": "2.0000000",
+			}},
+		},
+	}
+}
+
+// extendFootprintTTLCase extends the TTL of a contract instance and of contract
+// code. A third read-only key whose TTL did not change is left out.
+func extendFootprintTTLCase() effectsTestCase {
+	contractHash := xdr.Hash{0xcc}
+	dataKey := contractInstanceKey(contractHash)
+	codeKey := contractCodeKey(xdr.Hash{0xdd})
+	unchangedKey := contractCodeKey(xdr.Hash{0xee})
+
+	return effectsTestCase{
+		name: "extend footprint ttl",
+		op: xdr.OperationBody{
+			Type:                 xdr.OperationTypeExtendFootprintTtl,
+			ExtendFootprintTtlOp: &xdr.ExtendFootprintTtlOp{ExtendTo: 1000},
+		},
+		result: xdr.OperationResultTr{
+			Type: xdr.OperationTypeExtendFootprintTtl,
+			ExtendFootprintTtlResult: &xdr.ExtendFootprintTtlResult{
+				Code: xdr.ExtendFootprintTtlResultCodeExtendFootprintTtlSuccess,
+			},
+		},
+		soroban: &xdr.SorobanTransactionData{Resources: xdr.SorobanResources{Footprint: xdr.LedgerFootprint{
+			ReadOnly: []xdr.LedgerKey{dataKey, codeKey, unchangedKey},
+		}}},
+		changes: joinChanges(
+			entryUpdated(ttlData(dataKey, 500), ttlData(dataKey, 1000)),
+			entryUpdated(ttlData(codeKey, 800), ttlData(codeKey, 1000)),
+		),
+		want: []wantEffect{
+			{typ: EffectExtendFootprintTtl, address: sourceAddress, details: map[string]interface{}{
+				"extend_to": 1000,
+				"entries": []map[string]interface{}{
+					{
+						"key_type":          "contract_data",
+						"contract_id":       strkey.MustEncode(strkey.VersionByteContract, contractHash[:]),
+						"durability":        "persistent",
+						"contract_instance": true,
+						"ledger_key":        mustMarshalBase64(dataKey),
+						"key_hash":          ledgerKeyHash(dataKey).HexString(),
+						"live_until_ledger": 1000,
+					},
+					{
+						"key_type":          "contract_code",
+						"wasm_hash":         xdr.Hash{0xdd}.HexString(),
+						"ledger_key":        mustMarshalBase64(codeKey),
+						"key_hash":          ledgerKeyHash(codeKey).HexString(),
+						"live_until_ledger": 1000,
+					},
+				},
+			}},
+		},
+	}
+}
+
+// restoreFootprintCase restores an archived contract instance
+func restoreFootprintCase() effectsTestCase {
+	contractHash := xdr.Hash{0xcc}
+	dataKey := contractInstanceKey(contractHash)
+
+	return effectsTestCase{
+		name: "restore footprint",
+		op: xdr.OperationBody{
+			Type:               xdr.OperationTypeRestoreFootprint,
+			RestoreFootprintOp: &xdr.RestoreFootprintOp{},
+		},
+		result: xdr.OperationResultTr{
+			Type: xdr.OperationTypeRestoreFootprint,
+			RestoreFootprintResult: &xdr.RestoreFootprintResult{
+				Code: xdr.RestoreFootprintResultCodeRestoreFootprintSuccess,
+			},
+		},
+		soroban: &xdr.SorobanTransactionData{Resources: xdr.SorobanResources{Footprint: xdr.LedgerFootprint{
+			ReadWrite: []xdr.LedgerKey{dataKey},
+		}}},
+		changes: entryUpdated(ttlData(dataKey, 5), ttlData(dataKey, 2000)),
+		want: []wantEffect{
+			{typ: EffectRestoreFootprint, address: sourceAddress, details: map[string]interface{}{
+				"entries": []map[string]interface{}{
+					{
+						"key_type":          "contract_data",
+						"contract_id":       strkey.MustEncode(strkey.VersionByteContract, contractHash[:]),
+						"durability":        "persistent",
+						"contract_instance": true,
+						"ledger_key":        mustMarshalBase64(dataKey),
+						"key_hash":          ledgerKeyHash(dataKey).HexString(),
+						"live_until_ledger": 2000,
+					},
+				},
+			}},
+		},
+	}
+}
+
+// testTransaction builds a successful transaction from the test case, sent by
+// sourceAddress as the first transaction of the ledger
+func testTransaction(tc effectsTestCase) ingest.LedgerTransaction {
+	tx := xdr.Transaction{
+		SourceAccount: xdr.MustMuxedAddress(sourceAddress),
+		SeqNum:        testSeqNum,
+		Operations:    []xdr.Operation{{SourceAccount: tc.source, Body: tc.op}},
+	}
+	if tc.soroban != nil {
+		tx.Ext = xdr.TransactionExt{V: 1, SorobanData: tc.soroban}
+	}
+
+	results := []xdr.OperationResult{{Code: xdr.OperationResultCodeOpInner, Tr: &tc.result}}
+	meta := xdr.TransactionMetaV3{Operations: []xdr.OperationMeta{{Changes: tc.changes}}}
+	if len(tc.events) > 0 {
+		meta.SorobanMeta = &xdr.SorobanTransactionMeta{Events: tc.events}
+	}
+
+	return ingest.LedgerTransaction{
+		Index: 1,
+		Envelope: xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1:   &xdr.TransactionV1Envelope{Tx: tx},
+		},
+		Result: xdr.TransactionResultPair{
+			TransactionHash: testTxHash,
+			Result: xdr.TransactionResult{Result: xdr.TransactionResultResult{
+				Code:    xdr.TransactionResultCodeTxSuccess,
+				Results: &results,
+			}},
+		},
+		UnsafeMeta: xdr.TransactionMeta{V: 3, V3: &meta},
+		Hash:       testTxHash,
+	}
+}
+
+// wrapInFeeBump turns the transaction into the inner transaction of a
+// fee-bump paid by feeSource
+func wrapInFeeBump(tx ingest.LedgerTransaction, feeSource xdr.MuxedAccount, innerHash xdr.Hash) ingest.LedgerTransaction {
+	inner := tx.Envelope.V1
+	tx.Envelope = xdr.TransactionEnvelope{
+		Type: xdr.EnvelopeTypeEnvelopeTypeTxFeeBump,
+		FeeBump: &xdr.FeeBumpTransactionEnvelope{
+			Tx: xdr.FeeBumpTransaction{
+				FeeSource: feeSource,
+				InnerTx:   xdr.FeeBumpTransactionInnerTx{Type: xdr.EnvelopeTypeEnvelopeTypeTx, V1: inner},
+			},
+		},
+	}
+
+	results := tx.Result.Result.Result.MustResults()
+	tx.Result.Result = xdr.TransactionResult{Result: xdr.TransactionResultResult{
+		Code: xdr.TransactionResultCodeTxFeeBumpInnerSuccess,
+		InnerResultPair: &xdr.InnerTransactionResultPair{
+			TransactionHash: innerHash,
+			Result: xdr.InnerTransactionResult{Result: xdr.InnerTransactionResultResult{
+				Code:    xdr.TransactionResultCodeTxSuccess,
+				Results: &results,
+			}},
+		},
+	}}
+	return tx
+}
+
+func testEffects(t *testing.T, tx ingest.LedgerTransaction) []EffectOutput {
+	t.Helper()
+	p := &EffectsProcessor{}
+	effects, err := p.generateEffects(&TransactionWrapper{
+		Transaction: tx,
+		LedgerSeq:   testLedgerSequence,
+		Network:     "testnet",
+		Passphrase:  network.TestNetworkPassphrase,
+		CloseTime:   time.Unix(1700000000, 0).UTC(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return effects
+}
+
+func checkEffects(t *testing.T, got []EffectOutput, want []wantEffect) {
+	t.Helper()
+	if len(got) != len(want) {
+		gotTypes := make([]string, 0, len(got))
+		for _, effect := range got {
+			gotTypes = append(gotTypes, effect.TypeString)
+		}
+		t.Fatalf("got %d effects %v, want %d", len(got), gotTypes, len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		if EffectType(g.Type) != w.typ || g.Address != w.address || g.AddressMuxed.String != w.addressMuxed {
+			t.Errorf("effect %d: got %s for %s (muxed %q), want %s for %s (muxed %q)",
+				i, g.TypeString, g.Address, g.AddressMuxed.String, EffectTypeNames[w.typ], w.address, w.addressMuxed)
+			continue
+		}
+		for key, value := range w.details {
+			if fmt.Sprint(g.Details[key]) != fmt.Sprint(value) {
+				t.Errorf("effect %d (%s): %s is %v, want %v", i, g.TypeString, key, g.Details[key], value)
+			}
+		}
+	}
+}
+
+func mustMuxed(address string, id uint64) xdr.MuxedAccount {
+	muxed, err := xdr.MuxedAccountFromAccountId(address, id)
+	if err != nil {
+		panic(err)
+	}
+	return muxed
+}
+
+func mustMarshalBase64(v interface{}) string {
+	b64, err := xdr.MarshalBase64(v)
+	if err != nil {
+		panic(err)
+	}
+	return b64
+}
+
+func joinChanges(changes ...xdr.LedgerEntryChanges) xdr.LedgerEntryChanges {
+	var joined xdr.LedgerEntryChanges
+	for _, c := range changes {
+		joined = append(joined, c...)
+	}
+	return joined
+}
+
+func entryCreated(data xdr.LedgerEntryData) xdr.LedgerEntryChanges {
+	entry := xdr.LedgerEntry{LastModifiedLedgerSeq: testLedgerSequence, Data: data}
+	return xdr.LedgerEntryChanges{{Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated, Created: &entry}}
+}
+
+func entryUpdated(pre, post xdr.LedgerEntryData) xdr.LedgerEntryChanges {
+	preEntry := xdr.LedgerEntry{LastModifiedLedgerSeq: testLedgerSequence - 1, Data: pre}
+	postEntry := xdr.LedgerEntry{LastModifiedLedgerSeq: testLedgerSequence, Data: post}
+	return xdr.LedgerEntryChanges{
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &preEntry},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: &postEntry},
+	}
+}
+
+func entryRemoved(pre xdr.LedgerEntryData) xdr.LedgerEntryChanges {
+	preEntry := xdr.LedgerEntry{LastModifiedLedgerSeq: testLedgerSequence - 1, Data: pre}
+	key, err := preEntry.LedgerKey()
+	if err != nil {
+		panic(err)
+	}
+	return xdr.LedgerEntryChanges{
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &preEntry},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryRemoved, Removed: &key},
+	}
+}
+
+func sponsoredEntry(data xdr.LedgerEntryData, sponsor string) *xdr.LedgerEntry {
+	sponsorID := xdr.MustAddress(sponsor)
+	return &xdr.LedgerEntry{
+		LastModifiedLedgerSeq: testLedgerSequence,
+		Data:                  data,
+		Ext: xdr.LedgerEntryExt{
+			V:  1,
+			V1: &xdr.LedgerEntryExtensionV1{SponsoringId: &sponsorID},
+		},
+	}
+}
+
+func accountData(address, homeDomain string, signers []xdr.Signer) xdr.LedgerEntryData {
+	return xdr.LedgerEntryData{
+		Type: xdr.LedgerEntryTypeAccount,
+		Account: &xdr.AccountEntry{
+			AccountId:  xdr.MustAddress(address),
+			Balance:    1000000000,
+			HomeDomain: xdr.String32(homeDomain),
+			Thresholds: xdr.Thresholds{1, 0, 0, 0},
+			Signers:    signers,
+		},
+	}
+}
+
+func trustLineData(account string, asset xdr.TrustLineAsset, balance xdr.Int64, flags xdr.Uint32) xdr.LedgerEntryData {
+	return xdr.LedgerEntryData{
+		Type: xdr.LedgerEntryTypeTrustline,
+		TrustLine: &xdr.TrustLineEntry{
+			AccountId: xdr.MustAddress(account),
+			Asset:     asset,
+			Balance:   balance,
+			Limit:     10000000000,
+			Flags:     flags,
+		},
+	}
+}
+
+func offerEntry(seller string, id xdr.Int64, selling, buying xdr.Asset, amount xdr.Int64) *xdr.OfferEntry {
+	return &xdr.OfferEntry{
+		SellerId: xdr.MustAddress(seller),
+		OfferId:  id,
+		Selling:  selling,
+		Buying:   buying,
+		Amount:   amount,
+		Price:    xdr.Price{N: 1, D: 2},
+	}
+}
+
+func offerData(seller string, id xdr.Int64, selling, buying xdr.Asset, amount xdr.Int64) xdr.LedgerEntryData {
+	return xdr.LedgerEntryData{Type: xdr.LedgerEntryTypeOffer, Offer: offerEntry(seller, id, selling, buying, amount)}
+}
+
+func testPoolID(assetA, assetB xdr.Asset) xdr.PoolId {
+	id, err := xdr.NewPoolId(assetA, assetB, testPoolFee)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+func poolData(assetA, assetB xdr.Asset, reserveA, reserveB, shares xdr.Int64) xdr.LedgerEntryData {
+	return xdr.LedgerEntryData{
+		Type: xdr.LedgerEntryTypeLiquidityPool,
+		LiquidityPool: &xdr.LiquidityPoolEntry{
+			LiquidityPoolId: testPoolID(assetA, assetB),
+			Body: xdr.LiquidityPoolEntryBody{
+				Type: xdr.LiquidityPoolTypeLiquidityPoolConstantProduct,
+				ConstantProduct: &xdr.LiquidityPoolEntryConstantProduct{
+					Params: xdr.LiquidityPoolConstantProductParameters{
+						AssetA: assetA, AssetB: assetB, Fee: testPoolFee,
+					},
+					ReserveA:                 reserveA,
+					ReserveB:                 reserveB,
+					TotalPoolShares:          shares,
+					PoolSharesTrustLineCount: 1,
+				},
+			},
+		},
+	}
+}
+
+// orderBookClaim is an offer of seller selling USD for XLM being crossed
+func orderBookClaim(seller string, offerID xdr.Int64, usdSold, xlmBought xdr.Int64) xdr.ClaimAtom {
+	return xdr.ClaimAtom{
+		Type: xdr.ClaimAtomTypeClaimAtomTypeOrderBook,
+		OrderBook: &xdr.ClaimOfferAtom{
+			SellerId:     xdr.MustAddress(seller),
+			OfferId:      offerID,
+			AssetSold:    usdAsset,
+			AmountSold:   usdSold,
+			AssetBought:  nativeAsset,
+			AmountBought: xlmBought,
+		},
+	}
+}
+
+// poolClaim is the XLM/USD pool selling USD for XLM
+func poolClaim(poolID xdr.PoolId, usdSold, xlmBought xdr.Int64) xdr.ClaimAtom {
+	return xdr.ClaimAtom{
+		Type: xdr.ClaimAtomTypeClaimAtomTypeLiquidityPool,
+		LiquidityPool: &xdr.ClaimLiquidityAtom{
+			LiquidityPoolId: poolID,
+			AssetSold:       usdAsset,
+			AmountSold:      usdSold,
+			AssetBought:     nativeAsset,
+			AmountBought:    xlmBought,
+		},
+	}
+}
+
+func unconditionalClaimant(destination string) xdr.Claimant {
+	return xdr.Claimant{
+		Type: xdr.ClaimantTypeClaimantTypeV0,
+		V0: &xdr.ClaimantV0{
+			Destination: xdr.MustAddress(destination),
+			Predicate:   xdr.ClaimPredicate{Type: xdr.ClaimPredicateTypeClaimPredicateUnconditional},
+		},
+	}
+}
+
+func claimableBalanceData(id xdr.ClaimableBalanceId, asset xdr.Asset, amount xdr.Int64, claimant string) xdr.LedgerEntryData {
+	return xdr.LedgerEntryData{
+		Type: xdr.LedgerEntryTypeClaimableBalance,
+		ClaimableBalance: &xdr.ClaimableBalanceEntry{
+			BalanceId: id,
+			Claimants: []xdr.Claimant{unconditionalClaimant(claimant)},
+			Asset:     asset,
+			Amount:    amount,
+		},
+	}
+}
+
+// The SEP-23 claimable balance test vector
+var (
+	sep23BalanceHash = xdr.Hash{
+		0x3f, 0x0c, 0x34, 0xbf, 0x93, 0xad, 0x0d, 0x99, 0x71, 0xd0, 0x4c, 0xcc, 0x90, 0xf7, 0x05, 0x51,
+		0x1c, 0x83, 0x8a, 0xad, 0x97, 0x34, 0xa4, 0xa2, 0xfb, 0x0d, 0x7a, 0x03, 0xfc, 0x7f, 0xe8, 0x9a,
+	}
+	sep23BalanceID       = xdr.ClaimableBalanceId{Type: xdr.ClaimableBalanceIdTypeClaimableBalanceIdTypeV0, V0: &sep23BalanceHash}
+	sep23BalanceIDHex    = "000000003f0c34bf93ad0d9971d04ccc90f705511c838aad9734a4a2fb0d7a03fc7fe89a"
+	sep23BalanceIDStrkey = "BAAD6DBUX6J22DMZOHIEZTEQ64CVCHEDRKWZONFEUL5Q26QD7R76RGR4TU"
+)
+
+func balanceIDStrings(id xdr.ClaimableBalanceId) (hexID, strkeyID string) {
+	hexID, err := xdr.MarshalHex(id)
+	if err != nil {
+		panic(err)
+	}
+	strkeyID, err = claimableBalanceIDStrkey(id)
+	if err != nil {
+		panic(err)
+	}
+	return hexID, strkeyID
+}
+
+// testRevokeBalanceID computes the ID of the claimable balance core creates for
+// a reserve redeemed by the first operation of a transaction sent by
+// sourceAddress
+func testRevokeBalanceID(poolID xdr.PoolId, asset xdr.Asset) xdr.ClaimableBalanceId {
+	preimage, err := xdr.HashIdPreimage{
+		Type: xdr.EnvelopeTypeEnvelopeTypePoolRevokeOpId,
+		RevokeId: &xdr.HashIdPreimageRevokeId{
+			SourceAccount:   xdr.MustAddress(sourceAddress),
+			SeqNum:          testSeqNum,
+			OpNum:           0,
+			LiquidityPoolId: poolID,
+			Asset:           asset,
+		},
+	}.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	hash := xdr.Hash(sha256.Sum256(preimage))
+	return xdr.ClaimableBalanceId{Type: xdr.ClaimableBalanceIdTypeClaimableBalanceIdTypeV0, V0: &hash}
+}
+
+func contractInstanceKey(contract xdr.Hash) xdr.LedgerKey {
+	return xdr.LedgerKey{
+		Type: xdr.LedgerEntryTypeContractData,
+		ContractData: &xdr.LedgerKeyContractData{
+			Contract:   xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &contract},
+			Key:        xdr.ScVal{Type: xdr.ScValTypeScvLedgerKeyContractInstance},
+			Durability: xdr.ContractDataDurabilityPersistent,
+		},
+	}
+}
+
+func contractCodeKey(hash xdr.Hash) xdr.LedgerKey {
+	return xdr.LedgerKey{
+		Type:         xdr.LedgerEntryTypeContractCode,
+		ContractCode: &xdr.LedgerKeyContractCode{Hash: hash},
+	}
+}
+
+func ledgerKeyHash(key xdr.LedgerKey) xdr.Hash {
+	raw, err := key.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	return sha256.Sum256(raw)
+}
+
+func ttlData(key xdr.LedgerKey, liveUntil xdr.Uint32) xdr.LedgerEntryData {
+	return xdr.LedgerEntryData{
+		Type: xdr.LedgerEntryTypeTtl,
+		Ttl:  &xdr.TtlEntry{KeyHash: ledgerKeyHash(key), LiveUntilLedgerSeq: liveUntil},
+	}
+}
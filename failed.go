@@ -0,0 +1,203 @@
+package main
+
+import (
+	"github.com/guregu/null"
+	"github.com/pkg/errors"
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/toid"
+	"github.com/stellar/go/xdr"
+)
+
+// FailedTransactionMode controls what the processor emits for transactions
+// whose result is not successful
+type FailedTransactionMode string
+
+const (
+	FailedTransactionsSkip   FailedTransactionMode = "skip"   // Emit nothing (default)
+	FailedTransactionsFee    FailedTransactionMode = "fee"    // Emit only the fee charged
+	FailedTransactionsRecord FailedTransactionMode = "record" // Emit a single transaction_failed record
+)
+
+// parseFailedTransactionMode validates the failed_transactions config value
+func parseFailedTransactionMode(value interface{}) (FailedTransactionMode, error) {
+	if value == nil {
+		return FailedTransactionsSkip, nil
+	}
+	mode, ok := value.(string)
+	if !ok {
+		return "", errors.Errorf("failed_transactions must be a string, got %T", value)
+	}
+	switch FailedTransactionMode(mode) {
+	case FailedTransactionsSkip, FailedTransactionsFee, FailedTransactionsRecord:
+		return FailedTransactionMode(mode), nil
+	default:
+		return "", errors.Errorf("invalid failed_transactions %q, expected one of skip, fee or record", mode)
+	}
+}
+
+// failedTransactionEffects builds the records emitted for a failed transaction.
+// They are transaction-level, so their operation ID is the transaction TOID.
+func (p *EffectsProcessor) failedTransactionEffects(wrapper *TransactionWrapper) ([]EffectOutput, error) {
+	tx := wrapper.Transaction
+
+	var effectType EffectType
+	switch p.failedTransactions {
+	case FailedTransactionsFee:
+		effectType = EffectTransactionFeeCharged
+	case FailedTransactionsRecord:
+		effectType = EffectTransactionFailed
+	default:
+		return []EffectOutput{}, nil
+	}
+
+	source := feeSource(tx)
+
+	// Prefer the fee actually taken from the fee source's balance when the
+	// fee changes are known and readable; validateFeeChanges has already
+	// reported any disagreement with the result
+	feeCharged := int64(tx.Result.Result.FeeCharged)
+	if delta, ok, err := feeSourceBalanceDelta(tx); err == nil && ok {
+		feeCharged = -delta
+	}
+
+	details := map[string]interface{}{
+		"result_code": int32(tx.Result.Result.Result.Code),
+		"fee_charged": amount.StringFromInt64(feeCharged),
+		"asset_type":  "native",
+	}
+	if effectType == EffectTransactionFailed {
+		codes, err := operationResultCodes(tx.Result)
+		if err != nil {
+			return nil, err
+		}
+		details["operation_result_codes"] = codes
+	}
+
+	var addressMuxed null.String
+	if source.Type == xdr.CryptoKeyTypeKeyTypeMuxedEd25519 {
+		addressMuxed = null.StringFrom(source.Address())
+	}
+	accountID := source.ToAccountId()
+	innerHash, isFeeBump := tx.InnerTransactionHash()
+	var feeAccount, feeAccountMuxed null.String
+	if isFeeBump {
+		address, _ := tx.FeeAccount()
+		feeAccount = null.StringFrom(address)
+		if muxed, ok := tx.FeeAccountMuxed(); ok {
+			feeAccountMuxed = null.StringFrom(muxed)
+		}
+	}
+	operationID := toid.New(int32(wrapper.LedgerSeq), int32(tx.Index), 0).ToInt64()
+
+	return []EffectOutput{{
+		Address:              accountID.Address(),
+		AddressMuxed:         addressMuxed,
+		OperationID:          operationID,
+		Details:              details,
+		Type:                 int32(effectType),
+		TypeString:           EffectTypeNames[effectType],
+		LedgerClosed:         wrapper.CloseTime,
+		LedgerSequence:       wrapper.LedgerSeq,
+		EffectIndex:          0,
+		EffectId:             effectID(operationID, 0),
+		PagingToken:          pagingToken(operationID, 0),
+		TransactionHash:      tx.Hash.HexString(),
+		InnerTransactionHash: null.NewString(innerHash, isFeeBump),
+		FeeAccount:           feeAccount,
+		FeeAccountMuxed:      feeAccountMuxed,
+		Network:              wrapper.Network,
+	}}, nil
+}
+
+// operationResultCodes returns the result code of every operation along with,
+// when the operation was applied (opINNER), its type and operation-specific
+// code. Codes are the numeric values of the protocol's XDR enums, which unlike
+// the SDK's symbol names never change.
+func operationResultCodes(result xdr.TransactionResultPair) ([]map[string]interface{}, error) {
+	results, ok := result.OperationResults()
+	if !ok {
+		// Transactions rejected before applying operations have no operation results
+		return []map[string]interface{}{}, nil
+	}
+
+	codes := make([]map[string]interface{}, 0, len(results))
+	for i, opResult := range results {
+		entry := map[string]interface{}{
+			"index": i,
+			"code":  int32(opResult.Code),
+		}
+		if tr, ok := opResult.GetTr(); ok {
+			code, err := operationResultTrCode(tr)
+			if err != nil {
+				return nil, errors.Wrapf(err, "error reading result code of operation %d", i)
+			}
+			entry["type"] = int32(tr.Type)
+			entry["inner_code"] = code
+		}
+		codes = append(codes, entry)
+	}
+	return codes, nil
+}
+
+// operationResultTrCode returns the operation-specific result code of an
+// applied operation
+func operationResultTrCode(tr xdr.OperationResultTr) (int32, error) {
+	switch tr.Type {
+	case xdr.OperationTypeCreateAccount:
+		return int32(tr.MustCreateAccountResult().Code), nil
+	case xdr.OperationTypePayment:
+		return int32(tr.MustPaymentResult().Code), nil
+	case xdr.OperationTypePathPaymentStrictReceive:
+		return int32(tr.MustPathPaymentStrictReceiveResult().Code), nil
+	case xdr.OperationTypeManageSellOffer:
+		return int32(tr.MustManageSellOfferResult().Code), nil
+	case xdr.OperationTypeCreatePassiveSellOffer:
+		return int32(tr.MustCreatePassiveSellOfferResult().Code), nil
+	case xdr.OperationTypeSetOptions:
+		return int32(tr.MustSetOptionsResult().Code), nil
+	case xdr.OperationTypeChangeTrust:
+		return int32(tr.MustChangeTrustResult().Code), nil
+	case xdr.OperationTypeAllowTrust:
+		return int32(tr.MustAllowTrustResult().Code), nil
+	case xdr.OperationTypeAccountMerge:
+		return int32(tr.MustAccountMergeResult().Code), nil
+	case xdr.OperationTypeInflation:
+		return int32(tr.MustInflationResult().Code), nil
+	case xdr.OperationTypeManageData:
+		return int32(tr.MustManageDataResult().Code), nil
+	case xdr.OperationTypeBumpSequence:
+		return int32(tr.MustBumpSeqResult().Code), nil
+	case xdr.OperationTypeManageBuyOffer:
+		return int32(tr.MustManageBuyOfferResult().Code), nil
+	case xdr.OperationTypePathPaymentStrictSend:
+		return int32(tr.MustPathPaymentStrictSendResult().Code), nil
+	case xdr.OperationTypeCreateClaimableBalance:
+		return int32(tr.MustCreateClaimableBalanceResult().Code), nil
+	case xdr.OperationTypeClaimClaimableBalance:
+		return int32(tr.MustClaimClaimableBalanceResult().Code), nil
+	case xdr.OperationTypeBeginSponsoringFutureReserves:
+		return int32(tr.MustBeginSponsoringFutureReservesResult().Code), nil
+	case xdr.OperationTypeEndSponsoringFutureReserves:
+		return int32(tr.MustEndSponsoringFutureReservesResult().Code), nil
+	case xdr.OperationTypeRevokeSponsorship:
+		return int32(tr.MustRevokeSponsorshipResult().Code), nil
+	case xdr.OperationTypeClawback:
+		return int32(tr.MustClawbackResult().Code), nil
+	case xdr.OperationTypeClawbackClaimableBalance:
+		return int32(tr.MustClawbackClaimableBalanceResult().Code), nil
+	case xdr.OperationTypeSetTrustLineFlags:
+		return int32(tr.MustSetTrustLineFlagsResult().Code), nil
+	case xdr.OperationTypeLiquidityPoolDeposit:
+		return int32(tr.MustLiquidityPoolDepositResult().Code), nil
+	case xdr.OperationTypeLiquidityPoolWithdraw:
+		return int32(tr.MustLiquidityPoolWithdrawResult().Code), nil
+	case xdr.OperationTypeInvokeHostFunction:
+		return int32(tr.MustInvokeHostFunctionResult().Code), nil
+	case xdr.OperationTypeExtendFootprintTtl:
+		return int32(tr.MustExtendFootprintTtlResult().Code), nil
+	case xdr.OperationTypeRestoreFootprint:
+		return int32(tr.MustRestoreFootprintResult().Code), nil
+	default:
+		return 0, errors.Errorf("unknown operation type: %d", tr.Type)
+	}
+}
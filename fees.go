@@ -0,0 +1,81 @@
+package main
+
+import (
+	"github.com/pkg/errors"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+)
+
+// feeSource returns the account that paid the transaction fee, which for
+// fee-bump transactions is the fee-bump source
+func feeSource(tx ingest.LedgerTransaction) xdr.MuxedAccount {
+	if tx.Envelope.IsFeeBump() {
+		return tx.Envelope.FeeBumpAccount()
+	}
+	return tx.Envelope.SourceAccount()
+}
+
+// feeSourceBalanceDelta returns the net change to the fee source's native
+// balance caused by fee processing and, for Soroban transactions, by the
+// refund applied after the transaction (TxChangesAfter). ok is false when the
+// transaction carries no fee changes.
+func feeSourceBalanceDelta(tx ingest.LedgerTransaction) (delta int64, ok bool, err error) {
+	if len(tx.FeeChanges) == 0 {
+		return 0, false, nil
+	}
+
+	changes := tx.GetFeeChanges()
+	if meta, isV3 := tx.UnsafeMeta.GetV3(); isV3 {
+		changes = append(changes, ingest.GetChangesFromLedgerEntryChanges(meta.TxChangesAfter)...)
+	}
+
+	source := feeSource(tx)
+	account := source.ToAccountId()
+	for _, c := range changes {
+		if c.Type != xdr.LedgerEntryTypeAccount {
+			continue
+		}
+		if c.Pre == nil || c.Post == nil {
+			return 0, false, errors.Errorf("unexpected %s of account entry in fee changes", c.LedgerEntryChangeType())
+		}
+		pre := c.Pre.Data.MustAccount()
+		if !pre.AccountId.Equals(account) {
+			continue
+		}
+		post := c.Post.Data.MustAccount()
+		delta += int64(post.Balance - pre.Balance)
+	}
+	return delta, true, nil
+}
+
+// reportsNetFeeCharged reports whether the result's fee charged already has
+// the Soroban refund taken off. Protocol 20 reported the fee of Soroban
+// fee-bump transactions before the refund (stellar-core issue 4188); when the
+// protocol is unknown (0) these transactions are treated the same way.
+func reportsNetFeeCharged(tx ingest.LedgerTransaction) bool {
+	if _, isSoroban := tx.GetSorobanData(); !isSoroban || !tx.Envelope.IsFeeBump() {
+		return true
+	}
+	return tx.LedgerVersion >= 21
+}
+
+// validateFeeChanges checks that the fee source's balance went down by exactly
+// the fee reported in the transaction result. Transactions whose result does
+// not report the net fee are not checked.
+func validateFeeChanges(tx ingest.LedgerTransaction) error {
+	if !reportsNetFeeCharged(tx) {
+		return nil
+	}
+
+	delta, ok, err := feeSourceBalanceDelta(tx)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	if charged := int64(tx.Result.Result.FeeCharged); -delta != charged {
+		return errors.Errorf("fee changes charged %d stroops but the result reports %d", -delta, charged)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+)
+
+const (
+	feeSourceAddress = "GA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVSGZ"
+	otherAddress     = "GCEZWKCA5VLDNRLN3RPRJMRZOX3Z6G5CHCGSNFHEYVXM3XOJMDS674JZ"
+)
+
+func accountEntryChanges(address string, before, after xdr.Int64) xdr.LedgerEntryChanges {
+	entry := func(balance xdr.Int64) xdr.LedgerEntry {
+		return xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeAccount,
+				Account: &xdr.AccountEntry{
+					AccountId: xdr.MustAddress(address),
+					Balance:   balance,
+				},
+			},
+		}
+	}
+	pre, post := entry(before), entry(after)
+	return xdr.LedgerEntryChanges{
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &pre},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: &post},
+	}
+}
+
+func feeTestTransaction(feeCharged xdr.Int64, feeChanges, changesAfter xdr.LedgerEntryChanges) ingest.LedgerTransaction {
+	return ingest.LedgerTransaction{
+		Envelope: xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1: &xdr.TransactionV1Envelope{
+				Tx: xdr.Transaction{SourceAccount: xdr.MustMuxedAddress(feeSourceAddress)},
+			},
+		},
+		Result: xdr.TransactionResultPair{
+			Result: xdr.TransactionResult{FeeCharged: feeCharged},
+		},
+		UnsafeMeta: xdr.TransactionMeta{
+			V:  3,
+			V3: &xdr.TransactionMetaV3{TxChangesAfter: changesAfter},
+		},
+		FeeChanges: feeChanges,
+	}
+}
+
+// sorobanFeeBump turns the transaction into a Soroban fee-bump transaction
+// paid by the same account
+func sorobanFeeBump(tx ingest.LedgerTransaction, ledgerVersion uint32) ingest.LedgerTransaction {
+	inner := *tx.Envelope.V1
+	inner.Tx.Ext = xdr.TransactionExt{V: 1, SorobanData: &xdr.SorobanTransactionData{}}
+	tx.Envelope = xdr.TransactionEnvelope{
+		Type: xdr.EnvelopeTypeEnvelopeTypeTxFeeBump,
+		FeeBump: &xdr.FeeBumpTransactionEnvelope{
+			Tx: xdr.FeeBumpTransaction{
+				FeeSource: xdr.MustMuxedAddress(feeSourceAddress),
+				InnerTx: xdr.FeeBumpTransactionInnerTx{
+					Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+					V1:   &inner,
+				},
+			},
+		},
+	}
+	tx.LedgerVersion = ledgerVersion
+	return tx
+}
+
+func TestFeeSourceBalanceDelta(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		tx        ingest.LedgerTransaction
+		wantDelta int64
+		wantOK    bool
+	}{
+		{
+			name:   "no fee changes",
+			tx:     feeTestTransaction(100, nil, nil),
+			wantOK: false,
+		},
+		{
+			name:      "fee processing only",
+			tx:        feeTestTransaction(100, accountEntryChanges(feeSourceAddress, 1000, 900), nil),
+			wantDelta: -100,
+			wantOK:    true,
+		},
+		{
+			name: "refund in TxChangesAfter",
+			tx: feeTestTransaction(50,
+				accountEntryChanges(feeSourceAddress, 1000, 900),
+				accountEntryChanges(feeSourceAddress, 900, 950)),
+			wantDelta: -50,
+			wantOK:    true,
+		},
+		{
+			name: "other accounts are ignored",
+			tx: feeTestTransaction(100,
+				append(accountEntryChanges(feeSourceAddress, 1000, 900), accountEntryChanges(otherAddress, 500, 0)...),
+				accountEntryChanges(otherAddress, 0, 500)),
+			wantDelta: -100,
+			wantOK:    true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			delta, ok, err := feeSourceBalanceDelta(tc.tx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tc.wantOK || delta != tc.wantDelta {
+				t.Errorf("got (%d, %v), want (%d, %v)", delta, ok, tc.wantDelta, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestValidateFeeChanges(t *testing.T) {
+	refunded := feeTestTransaction(50,
+		accountEntryChanges(feeSourceAddress, 1000, 900),
+		accountEntryChanges(feeSourceAddress, 900, 950))
+	// Protocol 20 reported the fee before the refund
+	grossFee := refunded
+	grossFee.Result.Result.FeeCharged = 100
+
+	for _, tc := range []struct {
+		name    string
+		tx      ingest.LedgerTransaction
+		wantErr bool
+	}{
+		{"no fee changes", feeTestTransaction(100, nil, nil), false},
+		{"matching fee", feeTestTransaction(100, accountEntryChanges(feeSourceAddress, 1000, 900), nil), false},
+		{"mismatched fee", feeTestTransaction(90, accountEntryChanges(feeSourceAddress, 1000, 900), nil), true},
+		{"matching fee after refund", refunded, false},
+		{"fee before refund", grossFee, true},
+		{"protocol 20 soroban fee-bump is not checked", sorobanFeeBump(grossFee, 20), false},
+		{"unknown protocol soroban fee-bump is not checked", sorobanFeeBump(grossFee, 0), false},
+		{"protocol 21 soroban fee-bump is checked", sorobanFeeBump(grossFee, 21), true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateFeeChanges(tc.tx)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error %v", err, tc.wantErr)
+			}
+		})
+	}
+}
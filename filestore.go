@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/stellar/go/support/datastore"
+)
+
+// filesystemDataStore is a datastore.DataStore over a local directory laid
+// out like a galexie bucket. The vendored datastore package only provides a
+// GCS implementation.
+type filesystemDataStore struct {
+	root   string
+	schema datastore.DataStoreSchema
+}
+
+var _ datastore.DataStore = (*filesystemDataStore)(nil)
+
+// newFilesystemDataStore returns a data store reading objects below root
+func newFilesystemDataStore(root string, schema datastore.DataStoreSchema) (*filesystemDataStore, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error opening datastore directory %s", root)
+	}
+	if !info.IsDir() {
+		return nil, errors.Errorf("datastore path %s is not a directory", root)
+	}
+	return &filesystemDataStore{root: root, schema: schema}, nil
+}
+
+func (s *filesystemDataStore) path(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(key))
+}
+
+// GetFileMetadata returns an empty metadata map, as local files carry none
+func (s *filesystemDataStore) GetFileMetadata(ctx context.Context, key string) (map[string]string, error) {
+	if _, err := os.Stat(s.path(key)); err != nil {
+		return nil, err
+	}
+	return map[string]string{}, nil
+}
+
+// GetFile opens the object. Missing objects return an error wrapping
+// os.ErrNotExist, which the ledger backend relies on.
+func (s *filesystemDataStore) GetFile(ctx context.Context, key string) (io.ReadCloser, error) {
+	return os.Open(s.path(key))
+}
+
+// PutFile writes the object through a temporary file so readers never see a
+// partial object. Metadata is not stored.
+func (s *filesystemDataStore) PutFile(ctx context.Context, key string, in io.WriterTo, metaData map[string]string) error {
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.Wrapf(err, "error creating directory for %s", key)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return errors.Wrapf(err, "error creating temporary file for %s", key)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := in.WriteTo(tmp); err != nil {
+		tmp.Close()
+		return errors.Wrapf(err, "error writing %s", key)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrapf(err, "error closing %s", key)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// PutFileIfNotExists writes the object unless it already exists
+func (s *filesystemDataStore) PutFileIfNotExists(ctx context.Context, key string, in io.WriterTo, metaData map[string]string) (bool, error) {
+	exists, err := s.Exists(ctx, key)
+	if err != nil || exists {
+		return false, err
+	}
+	if err := s.PutFile(ctx, key, in, metaData); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Exists reports whether the object exists
+func (s *filesystemDataStore) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := os.Stat(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Size returns the size of the object in bytes
+func (s *filesystemDataStore) Size(ctx context.Context, key string) (int64, error) {
+	info, err := os.Stat(s.path(key))
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// GetSchema returns the layout of the ledger files
+func (s *filesystemDataStore) GetSchema() datastore.DataStoreSchema {
+	return s.schema
+}
+
+// Close releases nothing, local files are opened per call
+func (s *filesystemDataStore) Close() error {
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/xdr"
+)
+
+// attachTransactionHash computes the transaction hash from the envelope with
+// the network passphrase and stores it on the transaction. A hash that does
+// not match the one recorded in the result, or the one given in the input,
+// almost always means the configured passphrase is for the wrong network.
+func attachTransactionHash(wrapper *TransactionWrapper, inputHash string) error {
+	hash, err := network.HashTransactionInEnvelope(wrapper.Transaction.Envelope, wrapper.Passphrase)
+	if err != nil {
+		return NewProcessorError(
+			fmt.Errorf("error hashing transaction envelope: %w", err),
+			ErrorTypeProcessing,
+			ErrorSeverityError,
+		).WithLedger(wrapper.LedgerSeq)
+	}
+	computed := xdr.Hash(hash)
+
+	mismatch := func(source, expected string) error {
+		return NewProcessorError(
+			fmt.Errorf("transaction hash %s does not match the %s hash %s, check the network passphrase", computed.HexString(), source, expected),
+			ErrorTypeConfiguration,
+			ErrorSeverityError,
+		).WithTransaction(expected).
+			WithLedger(wrapper.LedgerSeq).
+			WithContext("computed_hash", computed.HexString()).
+			WithContext("network", wrapper.Network).
+			WithContext("network_passphrase", wrapper.Passphrase)
+	}
+
+	if resultHash := wrapper.Transaction.Result.TransactionHash; resultHash != computed {
+		return mismatch("result", resultHash.HexString())
+	}
+	if inputHash != "" && !strings.EqualFold(inputHash, computed.HexString()) {
+		return mismatch("input", inputHash)
+	}
+
+	wrapper.Transaction.Hash = computed
+	return nil
+}
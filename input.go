@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stellar/go/toid"
+)
+
+// TransactionInput is the JSON payload describing a single transaction.
+// Every field is kept raw so that a wrong-typed value is reported with the
+// other invalid fields instead of failing the whole decode, and so that
+// ledger sequence, transaction index and close time accept both numbers and
+// strings.
+type TransactionInput struct {
+	Hash            json.RawMessage `json:"hash"`
+	EnvelopeXDR     json.RawMessage `json:"envelope_xdr"`
+	ResultXDR       json.RawMessage `json:"result_xdr"`
+	MetaXDR         json.RawMessage `json:"meta_xdr"`
+	FeeMetaXDR      json.RawMessage `json:"fee_meta_xdr"`
+	LedgerSequence  json.RawMessage `json:"ledger_sequence"`
+	TxIndex         json.RawMessage `json:"tx_index"`
+	LedgerCloseTime json.RawMessage `json:"ledger_close_time"`
+}
+
+// hash returns the hash given in the input, or "" when it is missing or not
+// a string
+func (in *TransactionInput) hash() string {
+	hash, _ := parseStringField(in.Hash, false)
+	return hash
+}
+
+// transactionHash returns the hash given in the input, for error reporting
+func (in *TransactionInput) transactionHash() string {
+	if hash := in.hash(); hash != "" {
+		return hash
+	}
+	return "unknown"
+}
+
+// fieldErrors collects the validation failures of an input, keyed by field
+type fieldErrors map[string]string
+
+func (f fieldErrors) add(field string, err error) {
+	f[field] = err.Error()
+}
+
+// processorError reports every invalid field at once, each as error context
+func (f fieldErrors) processorError() *ProcessorError {
+	fields := make([]string, 0, len(f))
+	for field := range f {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	procErr := NewProcessorError(
+		fmt.Errorf("invalid transaction input: %s", strings.Join(fields, ", ")),
+		ErrorTypeParsing,
+		ErrorSeverityError,
+	)
+	for _, field := range fields {
+		procErr.WithContext(field, f[field])
+	}
+	return procErr
+}
+
+var errMissingField = errors.New("missing")
+
+func isMissing(raw json.RawMessage) bool {
+	return len(raw) == 0 || string(raw) == "null"
+}
+
+// parseStringField reads a string field. Optional fields that are missing
+// read as "".
+func parseStringField(raw json.RawMessage, required bool) (string, error) {
+	if isMissing(raw) {
+		if required {
+			return "", errMissingField
+		}
+		return "", nil
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", errors.New("must be a string")
+	}
+	if value == "" && required {
+		return "", errMissingField
+	}
+	return value, nil
+}
+
+// checkTransactionHash checks that a hash given in the input is 64 hex
+// characters, so that a malformed hash is reported as bad input rather than as
+// a hash mismatch
+func checkTransactionHash(hash string) error {
+	if len(hash) != 64 {
+		return errors.Errorf("must be 64 hex characters, got %d characters", len(hash))
+	}
+	if _, err := hex.DecodeString(hash); err != nil {
+		return errors.New("must be 64 hex characters")
+	}
+	return nil
+}
+
+// parseIntegerField reads an integer given either as a JSON number or as a
+// decimal string, and checks it is within [min, max]
+func parseIntegerField(raw json.RawMessage, min, max int64) (int64, error) {
+	if isMissing(raw) {
+		return 0, errMissingField
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err != nil {
+		var number json.Number
+		if err := json.Unmarshal(raw, &number); err != nil {
+			return 0, errors.New("must be a number or a numeric string")
+		}
+		text = number.String()
+	}
+
+	value, err := strconv.ParseInt(strings.TrimSpace(text), 10, 64)
+	if err != nil {
+		return 0, errors.Errorf("%q is not an integer", text)
+	}
+	if err := checkRange(value, min, max); err != nil {
+		return 0, err
+	}
+	return value, nil
+}
+
+// checkRange checks that value is within [min, max]
+func checkRange(value, min, max int64) error {
+	if value < min || value > max {
+		return errors.Errorf("%d is out of range [%d, %d]", value, min, max)
+	}
+	return nil
+}
+
+// parseCloseTime reads a close time given either as an RFC3339 string or as
+// unix seconds (a JSON number or a numeric string)
+func parseCloseTime(raw json.RawMessage) (time.Time, error) {
+	if isMissing(raw) {
+		return time.Time{}, errMissingField
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		if closeTime, err := time.Parse(time.RFC3339, text); err == nil {
+			return closeTime, nil
+		}
+	}
+
+	seconds, err := parseIntegerField(raw, 0, math.MaxInt64)
+	if err != nil {
+		return time.Time{}, errors.New("must be an RFC3339 timestamp or unix seconds")
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
+
+// parseLedgerSequence reads the ledger sequence, which must fit a TOID
+func parseLedgerSequence(raw json.RawMessage) (uint32, error) {
+	seq, err := parseIntegerField(raw, 1, math.MaxInt32)
+	return uint32(seq), err
+}
+
+// parseTxIndex reads the 1-based application order of the transaction
+func parseTxIndex(raw json.RawMessage) (uint32, error) {
+	index, err := parseIntegerField(raw, 1, toid.TransactionMask)
+	return uint32(index), err
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/xdr"
+)
+
+func TestParseIntegerField(t *testing.T) {
+	for _, tc := range []struct {
+		raw     string
+		want    int64
+		wantErr bool
+	}{
+		{raw: `42`, want: 42},
+		{raw: `"42"`, want: 42},
+		{raw: `" 42 "`, want: 42},
+		{raw: `2.5`, wantErr: true},
+		{raw: `"abc"`, wantErr: true},
+		{raw: `true`, wantErr: true},
+		{raw: `0`, wantErr: true},   // below the minimum
+		{raw: `101`, wantErr: true}, // above the maximum
+		{raw: `null`, wantErr: true},
+		{raw: ``, wantErr: true},
+	} {
+		got, err := parseIntegerField(json.RawMessage(tc.raw), 1, 100)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("parseIntegerField(%s) = (%d, %v), want (%d, error %v)", tc.raw, got, err, tc.want, tc.wantErr)
+		}
+	}
+
+	if _, err := parseIntegerField(nil, 1, 100); !errors.Is(err, errMissingField) {
+		t.Errorf("missing field: got %v, want %v", err, errMissingField)
+	}
+}
+
+func TestParseCloseTime(t *testing.T) {
+	want := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, tc := range []struct {
+		raw     string
+		wantErr bool
+	}{
+		{raw: `"2024-01-02T03:04:05Z"`},
+		{raw: `"2024-01-02T04:04:05+01:00"`},
+		{raw: `1704164645`},
+		{raw: `"1704164645"`},
+		{raw: `"yesterday"`, wantErr: true},
+		{raw: `-1`, wantErr: true},
+		{raw: `null`, wantErr: true},
+	} {
+		got, err := parseCloseTime(json.RawMessage(tc.raw))
+		if (err != nil) != tc.wantErr {
+			t.Errorf("parseCloseTime(%s): got error %v, want error %v", tc.raw, err, tc.wantErr)
+			continue
+		}
+		if !tc.wantErr && !got.Equal(want) {
+			t.Errorf("parseCloseTime(%s) = %s, want %s", tc.raw, got, want)
+		}
+	}
+}
+
+func TestParseTransactionReportsEveryField(t *testing.T) {
+	var input TransactionInput
+	payload := `{"envelope_xdr":1,"result_xdr":2,"hash":3,"ledger_sequence":"5","tx_index":"x","ledger_close_time":"soon"}`
+	if err := json.Unmarshal([]byte(payload), &input); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &EffectsProcessor{}
+	_, err := p.parseTransaction(&input, networkConfig{Name: "testnet", Passphrase: "Test SDF Network ; September 2015"})
+	var procErr *ProcessorError
+	if !errors.As(err, &procErr) {
+		t.Fatalf("got %v, want a ProcessorError", err)
+	}
+	if procErr.Type != ErrorTypeParsing {
+		t.Errorf("got error type %s, want %s", procErr.Type, ErrorTypeParsing)
+	}
+	if procErr.LedgerSequence != 5 {
+		t.Errorf("got ledger %d, want 5", procErr.LedgerSequence)
+	}
+	for _, field := range []string{"envelope_xdr", "result_xdr", "meta_xdr", "hash", "tx_index", "ledger_close_time"} {
+		if _, ok := procErr.Context[field]; !ok {
+			t.Errorf("field %s is not reported in %v", field, procErr.Context)
+		}
+	}
+	if _, ok := procErr.Context["ledger_sequence"]; ok {
+		t.Errorf("valid ledger_sequence is reported as invalid: %v", procErr.Context["ledger_sequence"])
+	}
+	if _, ok := procErr.Context["fee_meta_xdr"]; ok {
+		t.Error("missing optional fee_meta_xdr is reported as invalid")
+	}
+}
+
+func TestCheckLedgerEntryChanges(t *testing.T) {
+	paired := accountEntryChanges(feeSourceAddress, 100, 50)
+	other := accountEntryChanges(otherAddress, 100, 50)
+	state := paired[0].MustState()
+	removedKey, err := state.LedgerKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		changes xdr.LedgerEntryChanges
+		wantErr bool
+	}{
+		{name: "empty"},
+		{name: "state then updated", changes: paired},
+		{name: "two entries", changes: append(append(xdr.LedgerEntryChanges{}, paired...), other...)},
+		{
+			name: "state then removed",
+			changes: xdr.LedgerEntryChanges{
+				paired[0],
+				{Type: xdr.LedgerEntryChangeTypeLedgerEntryRemoved, Removed: &removedKey},
+			},
+		},
+		{name: "updated without state", changes: paired[1:], wantErr: true},
+		{
+			name:    "removed without state",
+			changes: xdr.LedgerEntryChanges{{Type: xdr.LedgerEntryChangeTypeLedgerEntryRemoved, Removed: &removedKey}},
+			wantErr: true,
+		},
+		{name: "updated after another entry's state", changes: xdr.LedgerEntryChanges{other[0], paired[1]}, wantErr: true},
+		{name: "updated after updated", changes: xdr.LedgerEntryChanges{paired[0], paired[1], paired[1]}, wantErr: true},
+	} {
+		if err := checkLedgerEntryChanges(tc.changes); (err != nil) != tc.wantErr {
+			t.Errorf("%s: got error %v, want error %v", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestParseTransactionRejectsUnpairedFeeChanges(t *testing.T) {
+	feeMeta, err := xdr.MarshalBase64(accountEntryChanges(feeSourceAddress, 100, 50)[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := TransactionInput{FeeMetaXDR: json.RawMessage(`"` + feeMeta + `"`)}
+
+	p := &EffectsProcessor{}
+	_, err = p.parseTransaction(&input, networkConfig{Name: "testnet", Passphrase: "Test SDF Network ; September 2015"})
+	var procErr *ProcessorError
+	if !errors.As(err, &procErr) {
+		t.Fatalf("got %v, want a ProcessorError", err)
+	}
+	if _, ok := procErr.Context["fee_meta_xdr"]; !ok {
+		t.Errorf("unpaired fee change is not reported in %v", procErr.Context)
+	}
+}
+
+func TestParseTransactionRejectsUnpairedMetaChanges(t *testing.T) {
+	unpaired := accountEntryChanges(feeSourceAddress, 100, 50)[1:]
+	for _, tc := range []struct {
+		name string
+		meta xdr.TransactionMeta
+	}{
+		{
+			name: "operation changes",
+			meta: xdr.TransactionMeta{V: 3, V3: &xdr.TransactionMetaV3{
+				Operations: []xdr.OperationMeta{{}, {Changes: unpaired}},
+			}},
+		},
+		{
+			name: "changes before",
+			meta: xdr.TransactionMeta{V: 2, V2: &xdr.TransactionMetaV2{TxChangesBefore: unpaired}},
+		},
+		{
+			name: "changes after",
+			meta: xdr.TransactionMeta{V: 3, V3: &xdr.TransactionMetaV3{TxChangesAfter: unpaired}},
+		},
+		{
+			name: "v1 changes",
+			meta: xdr.TransactionMeta{V: 1, V1: &xdr.TransactionMetaV1{TxChanges: unpaired}},
+		},
+	} {
+		meta, err := xdr.MarshalBase64(tc.meta)
+		if err != nil {
+			t.Fatal(err)
+		}
+		input := TransactionInput{MetaXDR: json.RawMessage(`"` + meta + `"`)}
+
+		p := &EffectsProcessor{}
+		_, err = p.parseTransaction(&input, networkConfig{Name: "testnet", Passphrase: "Test SDF Network ; September 2015"})
+		var procErr *ProcessorError
+		if !errors.As(err, &procErr) {
+			t.Fatalf("%s: got %v, want a ProcessorError", tc.name, err)
+		}
+		if _, ok := procErr.Context["meta_xdr"]; !ok {
+			t.Errorf("%s: unpaired meta change is not reported in %v", tc.name, procErr.Context)
+		}
+	}
+}
+
+func TestCheckTransactionHash(t *testing.T) {
+	for _, tc := range []struct {
+		hash    string
+		wantErr bool
+	}{
+		{hash: "3389e9f0f1a65f19736cacf544c2e825313e8447f569233bb8db39aa607c8889"},
+		{hash: "3389E9F0F1A65F19736CACF544C2E825313E8447F569233BB8DB39AA607C8889"},
+		{hash: "0x3389e9f0f1a65f19736cacf544c2e825313e8447f569233bb8db39aa607c88", wantErr: true},
+		{hash: "3389e9f0f1a65f19736cacf544c2e825313e8447f569233bb8db39aa607c888", wantErr: true},
+		{hash: "3389e9f0f1a65f19736cacf544c2e825313e8447f569233bb8db39aa607c8889aa", wantErr: true},
+		{hash: "zz89e9f0f1a65f19736cacf544c2e825313e8447f569233bb8db39aa607c8889", wantErr: true},
+	} {
+		if err := checkTransactionHash(tc.hash); (err != nil) != tc.wantErr {
+			t.Errorf("checkTransactionHash(%q): got error %v, want error %v", tc.hash, err, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+)
+
+// InputFormat selects how message payloads are decoded
+type InputFormat string
+
+const (
+	InputFormatTransaction     InputFormat = "transaction"       // Per-transaction JSON (default)
+	InputFormatLedgerCloseMeta InputFormat = "ledger_close_meta" // A whole xdr.LedgerCloseMeta, raw or base64
+
+	// An xdr.LedgerCloseMetaBatch, compressed as named by the message metadata
+	InputFormatLedgerCloseMetaBatch InputFormat = "ledger_close_meta_batch"
+)
+
+// parseInputFormat validates the input_format config value
+func parseInputFormat(value interface{}) (InputFormat, error) {
+	if value == nil {
+		return InputFormatTransaction, nil
+	}
+	format, ok := value.(string)
+	if !ok {
+		return "", errors.Errorf("input_format must be a string, got %T", value)
+	}
+	switch InputFormat(format) {
+	case InputFormatTransaction, InputFormatLedgerCloseMeta, InputFormatLedgerCloseMetaBatch:
+		return InputFormat(format), nil
+	default:
+		return "", errors.Errorf("invalid input_format %q, expected transaction, ledger_close_meta or ledger_close_meta_batch", format)
+	}
+}
+
+// decodeLedgerCloseMeta reads a LedgerCloseMeta from either raw XDR bytes or
+// its base64 encoding
+func decodeLedgerCloseMeta(payload interface{}) (xdr.LedgerCloseMeta, error) {
+	var lcm xdr.LedgerCloseMeta
+
+	var data []byte
+	switch p := payload.(type) {
+	case []byte:
+		data = p
+	case string:
+		data = []byte(p)
+	default:
+		return lcm, errors.Errorf("expected payload to be []byte or string, got %T", payload)
+	}
+
+	if err := xdr.SafeUnmarshal(data, &lcm); err == nil {
+		return lcm, nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return lcm, errors.New("payload is neither LedgerCloseMeta XDR nor base64 encoded XDR")
+	}
+	if err := xdr.SafeUnmarshal(decoded, &lcm); err != nil {
+		return lcm, errors.Wrap(err, "error unmarshaling LedgerCloseMeta XDR")
+	}
+	return lcm, nil
+}
+
+// transformLedgerToEffects derives the effects of every transaction in the
+// ledger, in application order
+func (p *EffectsProcessor) transformLedgerToEffects(ctx context.Context, lcm xdr.LedgerCloseMeta, net networkConfig) ([]EffectOutput, error) {
+	reader, err := ingest.NewLedgerTransactionReaderFromLedgerCloseMeta(net.Passphrase, lcm)
+	if err != nil {
+		return nil, NewProcessorError(
+			fmt.Errorf("error creating ledger transaction reader: %w", err),
+			ErrorTypeParsing,
+			ErrorSeverityError,
+		)
+	}
+	defer reader.Close()
+
+	closeTime := time.Unix(lcm.LedgerCloseTime(), 0).UTC()
+
+	effects := []EffectOutput{}
+	for {
+		// Check for context cancellation between transactions
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		tx, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// The reader matches envelopes to results by hashing them with the
+			// passphrase, so an unknown hash means the wrong network
+			if isUnknownTransactionHash(err) {
+				return nil, NewProcessorError(
+					fmt.Errorf("error reading transaction from ledger: %w", err),
+					ErrorTypeConfiguration,
+					ErrorSeverityError,
+				).WithContext("network", net.Name).
+					WithContext("network_passphrase", net.Passphrase)
+			}
+			return nil, NewProcessorError(
+				fmt.Errorf("error reading transaction from ledger: %w", err),
+				ErrorTypeParsing,
+				ErrorSeverityError,
+			)
+		}
+
+		txEffects, err := p.generateEffects(&TransactionWrapper{
+			Transaction: tx,
+			LedgerSeq:   lcm.LedgerSequence(),
+			Network:     net.Name,
+			Passphrase:  net.Passphrase,
+			CloseTime:   closeTime,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "error generating effects for transaction %s", tx.Hash.HexString())
+		}
+		effects = append(effects, txEffects...)
+	}
+
+	return effects, nil
+}
+
+// ledgerProcessorError attaches the ledger to an error from
+// transformLedgerToEffects, keeping the classification of processor errors
+func ledgerProcessorError(err error, sequence uint32) *ProcessorError {
+	var procErr *ProcessorError
+	if errors.As(err, &procErr) {
+		return procErr.WithLedger(sequence)
+	}
+	return NewProcessorError(
+		fmt.Errorf("error transforming ledger to effects: %w", err),
+		ErrorTypeProcessing,
+		ErrorSeverityError,
+	).WithLedger(sequence)
+}
+
+// isUnknownTransactionHash reports whether a LedgerTransactionReader error is
+// the one raised when no envelope hashes to a result's transaction hash. The
+// reader has no typed error for it, so its message is matched.
+func isUnknownTransactionHash(err error) bool {
+	return strings.Contains(err.Error(), "unknown tx hash in LedgerCloseMeta")
+}
@@ -6,15 +6,17 @@ import (
 	"fmt"
 	"log"
 
-	"github.com/pkg/errors"
 	"github.com/withObsrvr/pluginapi"
 )
 
 // EffectsProcessor is a Flow processor plugin that transforms operations into effects.
 type EffectsProcessor struct {
-	config            map[string]interface{}
-	networkPassphrase string
-	consumers         []pluginapi.Consumer
+	config             map[string]interface{}
+	networks           map[string]networkConfig
+	defaultNetwork     string
+	failedTransactions FailedTransactionMode
+	inputFormat        InputFormat
+	consumers          []pluginapi.Consumer
 }
 
 // Name returns the plugin's name.
@@ -46,6 +48,12 @@ type Effect {
     closedAt: String!
     ledgerSequence: Int!
     index: Int!
+    pagingToken: String!
+    transactionHash: String!
+    innerTransactionHash: String
+    feeAccount: String
+    feeAccountMuxed: String
+    network: String!
 }
 
 scalar JSON
@@ -58,6 +66,7 @@ func (p *EffectsProcessor) GetQueryDefinitions() string {
     effectsByOperationId(operationId: Int!): [Effect]
     effectsByAddress(address: String!): [Effect]
     effectsByType(type: Int!): [Effect]
+    effectsByTransactionHash(hash: String!): [Effect]
 `
 }
 
@@ -66,12 +75,25 @@ func (p *EffectsProcessor) Initialize(config map[string]interface{}) error {
 	p.config = config
 	p.consumers = make([]pluginapi.Consumer, 0)
 
-	// Extract network passphrase from config
-	if passphrase, ok := config["network_passphrase"].(string); ok {
-		p.networkPassphrase = passphrase
-	} else {
-		return errors.New("network_passphrase is required in configuration")
+	// Extract the networks and their passphrases from config
+	networks, defaultNetwork, err := parseNetworks(config)
+	if err != nil {
+		return NewProcessorError(err, ErrorTypeConfiguration, ErrorSeverityError)
 	}
+	p.networks = networks
+	p.defaultNetwork = defaultNetwork
+
+	mode, err := parseFailedTransactionMode(config["failed_transactions"])
+	if err != nil {
+		return NewProcessorError(err, ErrorTypeConfiguration, ErrorSeverityError)
+	}
+	p.failedTransactions = mode
+
+	format, err := parseInputFormat(config["input_format"])
+	if err != nil {
+		return NewProcessorError(err, ErrorTypeConfiguration, ErrorSeverityError)
+	}
+	p.inputFormat = format
 
 	log.Println("EffectsProcessor initialized with config:", config)
 	return nil
@@ -94,37 +116,41 @@ func (p *EffectsProcessor) Process(ctx context.Context, msg pluginapi.Message) e
 		)
 	}
 
-	// Extract transaction data from the message
-	var transaction map[string]interface{}
-
-	// Use type assertion to convert payload to []byte
-	payloadBytes, ok := msg.Payload.([]byte)
-	if !ok {
-		return NewProcessorError(
-			fmt.Errorf("expected payload to be []byte, got %T", msg.Payload),
-			ErrorTypeParsing,
-			ErrorSeverityError,
-		)
+	net, err := p.resolveNetwork(msg.Metadata)
+	if err != nil {
+		return NewProcessorError(err, ErrorTypeConfiguration, ErrorSeverityError)
 	}
 
-	if err := json.Unmarshal(payloadBytes, &transaction); err != nil {
-		return NewProcessorError(
-			fmt.Errorf("error unmarshaling transaction: %w", err),
-			ErrorTypeParsing,
-			ErrorSeverityError,
-		)
+	var effects []EffectOutput
+	switch p.inputFormat {
+	case InputFormatLedgerCloseMetaBatch:
+		// Batches are emitted ledger by ledger rather than all at once
+		return p.processLedgerCloseMetaBatch(ctx, net, msg)
+	case InputFormatLedgerCloseMeta:
+		effects, err = p.processLedgerCloseMeta(ctx, net, msg.Payload)
+	default:
+		var isProtobuf bool
+		isProtobuf, err = isProtobufContentType(msg.Metadata)
+		if err != nil {
+			return NewProcessorError(err, ErrorTypeParsing, ErrorSeverityError)
+		}
+		if isProtobuf {
+			effects, err = p.processTransactionProto(ctx, net, msg.Payload)
+		} else {
+			effects, err = p.processTransactionJSON(ctx, net, msg.Payload)
+		}
 	}
-
-	// Process the transaction and generate effects
-	effects, err := p.transformTransactionToEffects(ctx, transaction)
 	if err != nil {
-		return NewProcessorError(
-			fmt.Errorf("error transforming transaction to effects: %w", err),
-			ErrorTypeProcessing,
-			ErrorSeverityError,
-		).WithTransaction(getTransactionHash(transaction))
+		return err
 	}
 
+	return p.emitEffects(ctx, msg, effects, false)
+}
+
+// emitEffects sends each effect to the registered consumers, carrying over
+// the metadata of the source message. Consumer errors are logged, or returned
+// when strict is set so that callers can stop instead of dropping effects.
+func (p *EffectsProcessor) emitEffects(ctx context.Context, msg pluginapi.Message, effects []EffectOutput, strict bool) error {
 	// If no effects, just return
 	if len(effects) == 0 {
 		return nil
@@ -147,6 +173,7 @@ func (p *EffectsProcessor) Process(ctx context.Context, msg pluginapi.Message) e
 			Metadata: map[string]interface{}{
 				"effect_id":   effect.EffectId,
 				"effect_type": effect.TypeString,
+				"network":     effect.Network,
 			},
 		}
 
@@ -158,6 +185,13 @@ func (p *EffectsProcessor) Process(ctx context.Context, msg pluginapi.Message) e
 		// Forward to consumers
 		for _, consumer := range p.consumers {
 			if err := consumer.Process(ctx, outputMsg); err != nil {
+				if strict {
+					return NewProcessorError(
+						fmt.Errorf("error in consumer %s: %w", consumer.Name(), err),
+						ErrorTypeIO,
+						ErrorSeverityError,
+					).WithTransaction(effect.TransactionHash).WithLedger(effect.LedgerSequence)
+				}
 				log.Printf("Error in consumer %s: %v", consumer.Name(), err)
 			}
 		}
@@ -166,20 +200,71 @@ func (p *EffectsProcessor) Process(ctx context.Context, msg pluginapi.Message) e
 	return nil
 }
 
+// processTransactionJSON derives the effects of a single transaction encoded as JSON
+func (p *EffectsProcessor) processTransactionJSON(ctx context.Context, net networkConfig, payload interface{}) ([]EffectOutput, error) {
+	// Use type assertion to convert payload to []byte
+	payloadBytes, ok := payload.([]byte)
+	if !ok {
+		return nil, NewProcessorError(
+			fmt.Errorf("expected payload to be []byte, got %T", payload),
+			ErrorTypeParsing,
+			ErrorSeverityError,
+		)
+	}
+
+	var input TransactionInput
+	if err := json.Unmarshal(payloadBytes, &input); err != nil {
+		return nil, NewProcessorError(
+			fmt.Errorf("error unmarshaling transaction: %w", err),
+			ErrorTypeParsing,
+			ErrorSeverityError,
+		).WithTransaction(input.transactionHash())
+	}
+
+	wrapper, err := p.parseTransaction(&input, net)
+	if err != nil {
+		return nil, err
+	}
+	if err := attachTransactionHash(wrapper, input.hash()); err != nil {
+		return nil, err
+	}
+
+	// Process the transaction and generate effects
+	effects, err := p.transformTransactionToEffects(ctx, wrapper)
+	if err != nil {
+		return nil, NewProcessorError(
+			fmt.Errorf("error transforming transaction to effects: %w", err),
+			ErrorTypeProcessing,
+			ErrorSeverityError,
+		).WithTransaction(input.transactionHash()).WithLedger(wrapper.LedgerSeq)
+	}
+	return effects, nil
+}
+
+// processLedgerCloseMeta derives the effects of every transaction in a LedgerCloseMeta payload
+func (p *EffectsProcessor) processLedgerCloseMeta(ctx context.Context, net networkConfig, payload interface{}) ([]EffectOutput, error) {
+	lcm, err := decodeLedgerCloseMeta(payload)
+	if err != nil {
+		return nil, NewProcessorError(
+			fmt.Errorf("error decoding ledger close meta: %w", err),
+			ErrorTypeParsing,
+			ErrorSeverityError,
+		)
+	}
+
+	effects, err := p.transformLedgerToEffects(ctx, lcm, net)
+	if err != nil {
+		return nil, ledgerProcessorError(err, lcm.LedgerSequence())
+	}
+	return effects, nil
+}
+
 // Close handles any cleanup if necessary.
 func (p *EffectsProcessor) Close() error {
 	log.Println("EffectsProcessor closed")
 	return nil
 }
 
-// Helper function to get transaction hash
-func getTransactionHash(transaction map[string]interface{}) string {
-	if hash, ok := transaction["hash"].(string); ok {
-		return hash
-	}
-	return "unknown"
-}
-
 // New is the exported function for dynamic plugin loading.
 func New() pluginapi.Plugin {
 	return &EffectsProcessor{}
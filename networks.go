@@ -0,0 +1,126 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/stellar/go/network"
+)
+
+// networkMetadataKey is the message metadata key naming the network a
+// message belongs to, one of the networks configured in Initialize
+const networkMetadataKey = "network"
+
+// wellKnownNetworks maps the network names that may be configured without a
+// passphrase to their passphrases
+var wellKnownNetworks = map[string]string{
+	"pubnet":    network.PublicNetworkPassphrase,
+	"testnet":   network.TestNetworkPassphrase,
+	"futurenet": network.FutureNetworkPassphrase,
+}
+
+// networkConfig is a named network the processor can ingest
+type networkConfig struct {
+	Name       string
+	Passphrase string
+}
+
+// parseNetworks reads the configured networks and the default network used
+// for messages that don't name one. Networks come from the networks map and
+// from network_passphrase, which is named after the matching well-known
+// network or "default" and becomes the default network.
+func parseNetworks(config map[string]interface{}) (map[string]networkConfig, string, error) {
+	networks := map[string]networkConfig{}
+
+	if raw, ok := config["networks"]; ok && raw != nil {
+		entries, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, "", errors.Errorf("networks must be a map of network names to passphrases, got %T", raw)
+		}
+		for name, value := range entries {
+			passphrase, ok := value.(string)
+			if !ok {
+				return nil, "", errors.Errorf("passphrase of network %q must be a string, got %T", name, value)
+			}
+			if passphrase == "" {
+				if passphrase, ok = wellKnownNetworks[name]; !ok {
+					return nil, "", errors.Errorf("network %q requires a passphrase", name)
+				}
+			}
+			networks[name] = networkConfig{Name: name, Passphrase: passphrase}
+		}
+	}
+
+	var defaultNetwork string
+	if raw, ok := config["network_passphrase"]; ok && raw != nil {
+		passphrase, ok := raw.(string)
+		if !ok || passphrase == "" {
+			return nil, "", errors.New("network_passphrase must be a non-empty string")
+		}
+		defaultNetwork = "default"
+		for name, known := range wellKnownNetworks {
+			if known == passphrase {
+				defaultNetwork = name
+			}
+		}
+		if existing, ok := networks[defaultNetwork]; ok && existing.Passphrase != passphrase {
+			return nil, "", errors.Errorf("network_passphrase conflicts with the passphrase of network %q", defaultNetwork)
+		}
+		networks[defaultNetwork] = networkConfig{Name: defaultNetwork, Passphrase: passphrase}
+	}
+
+	if raw, ok := config["default_network"]; ok && raw != nil {
+		name, ok := raw.(string)
+		if !ok {
+			return nil, "", errors.Errorf("default_network must be a string, got %T", raw)
+		}
+		if _, ok := networks[name]; !ok {
+			return nil, "", errors.Errorf("default_network %q is not a configured network", name)
+		}
+		defaultNetwork = name
+	}
+
+	if len(networks) == 0 {
+		return nil, "", errors.New("network_passphrase or networks is required in configuration")
+	}
+	return networks, defaultNetwork, nil
+}
+
+// resolveNetwork returns the network named by the message metadata, falling
+// back to the default network
+func (p *EffectsProcessor) resolveNetwork(metadata map[string]interface{}) (networkConfig, error) {
+	var name string
+	if value, ok := metadata[networkMetadataKey]; ok && value != nil {
+		if name, ok = value.(string); !ok {
+			return networkConfig{}, errors.Errorf("%s metadata must be a string, got %T", networkMetadataKey, value)
+		}
+	}
+	return p.lookupNetwork(name)
+}
+
+// lookupNetwork returns the named network, or the default network when name
+// is empty
+func (p *EffectsProcessor) lookupNetwork(name string) (networkConfig, error) {
+	if name == "" {
+		name = p.defaultNetwork
+	}
+	if name == "" {
+		return networkConfig{}, errors.Errorf("message has no %s metadata and no default network is configured", networkMetadataKey)
+	}
+
+	net, ok := p.networks[name]
+	if !ok {
+		return networkConfig{}, errors.Errorf("unknown network %q, expected one of %v", name, p.networkNames())
+	}
+	return net, nil
+}
+
+// networkNames returns the configured network names in order
+func (p *EffectsProcessor) networkNames() []string {
+	names := make([]string, 0, len(p.networks))
+	for name := range p.networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"crypto/sha256"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/toid"
+	"github.com/stellar/go/xdr"
+)
+
+// operationWrapper represents the data for a single operation within a transaction
+type operationWrapper struct {
+	index          uint32
+	transaction    ingest.LedgerTransaction
+	operation      xdr.Operation
+	result         xdr.OperationResult
+	changes        []ingest.Change
+	ledgerSequence uint32
+	network        string // network passphrase
+	networkName    string
+	ledgerClosed   time.Time
+}
+
+// ID returns the ID for the operation.
+func (o *operationWrapper) ID() int64 {
+	return toid.New(
+		int32(o.ledgerSequence),
+		int32(o.transaction.Index),
+		int32(o.index+1),
+	).ToInt64()
+}
+
+// TransactionHash returns the hex hash of the transaction computed with the
+// network passphrase, which for fee-bump transactions is the hash of the
+// outer envelope.
+func (o *operationWrapper) TransactionHash() string {
+	return o.transaction.Hash.HexString()
+}
+
+// SourceAccount returns the operation's source account, falling back to the
+// transaction source account when the operation does not set one.
+func (o *operationWrapper) SourceAccount() *xdr.MuxedAccount {
+	if o.operation.SourceAccount != nil {
+		return o.operation.SourceAccount
+	}
+	source := o.transaction.Envelope.SourceAccount()
+	return &source
+}
+
+// OperationType returns the operation type.
+func (o *operationWrapper) OperationType() xdr.OperationType {
+	return o.operation.Body.Type
+}
+
+// OperationResult returns the operation's result record
+func (o *operationWrapper) OperationResult() *xdr.OperationResultTr {
+	tr := o.result.MustTr()
+	return &tr
+}
+
+// contractEvents returns the contract events recorded in the transaction's
+// Soroban meta. Soroban transactions carry a single operation, so these are
+// the events of that operation.
+func (o *operationWrapper) contractEvents() []xdr.ContractEvent {
+	meta, ok := o.transaction.UnsafeMeta.GetV3()
+	if !ok || meta.SorobanMeta == nil {
+		return nil
+	}
+
+	var events []xdr.ContractEvent
+	for _, event := range meta.SorobanMeta.Events {
+		if event.Type != xdr.ContractEventTypeContract {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// footprintTTLChanges returns the details of every contract data and contract
+// code key in the footprint whose TTL entry was changed by the operation
+func (o *operationWrapper) footprintTTLChanges(footprint []xdr.LedgerKey) ([]map[string]interface{}, error) {
+	liveUntil := map[xdr.Hash]uint32{}
+	for _, c := range o.changes {
+		if c.Type != xdr.LedgerEntryTypeTtl || c.Post == nil {
+			continue
+		}
+		ttl := c.Post.Data.MustTtl()
+		liveUntil[ttl.KeyHash] = uint32(ttl.LiveUntilLedgerSeq)
+	}
+
+	entries := make([]map[string]interface{}, 0, len(footprint))
+	for _, key := range footprint {
+		if key.Type != xdr.LedgerEntryTypeContractData && key.Type != xdr.LedgerEntryTypeContractCode {
+			continue
+		}
+		keyBytes, err := key.MarshalBinary()
+		if err != nil {
+			return nil, errors.Wrap(err, "error marshaling footprint ledger key")
+		}
+		keyHash := xdr.Hash(sha256.Sum256(keyBytes))
+		ledger, ok := liveUntil[keyHash]
+		if !ok {
+			continue
+		}
+
+		details, err := contractLedgerKeyDetails(key)
+		if err != nil {
+			return nil, err
+		}
+		details["key_hash"] = keyHash.HexString()
+		details["live_until_ledger"] = ledger
+		entries = append(entries, details)
+	}
+	return entries, nil
+}
+
+// accountChange returns the change to the given account made by the operation
+func (o *operationWrapper) accountChange(account xdr.AccountId) (ingest.Change, bool) {
+	for _, c := range o.changes {
+		if c.Type != xdr.LedgerEntryTypeAccount {
+			continue
+		}
+		entry := c.Post
+		if entry == nil {
+			entry = c.Pre
+		}
+		accountEntry := entry.Data.MustAccount()
+		if accountEntry.AccountId.Equals(account) {
+			return c, true
+		}
+	}
+	return ingest.Change{}, false
+}
+
+// offerChange returns the change to the given offer made by the operation
+func (o *operationWrapper) offerChange(seller xdr.AccountId, offerID xdr.Int64) (ingest.Change, bool) {
+	for _, c := range o.changes {
+		if c.Type != xdr.LedgerEntryTypeOffer {
+			continue
+		}
+		entry := c.Post
+		if entry == nil {
+			entry = c.Pre
+		}
+		offer := entry.Data.MustOffer()
+		if offer.OfferId == offerID && offer.SellerId.Equals(seller) {
+			return c, true
+		}
+	}
+	return ingest.Change{}, false
+}
+
+// removedClaimableBalance returns the state of the given claimable balance
+// before the operation removed it
+func (o *operationWrapper) removedClaimableBalance(balanceID xdr.ClaimableBalanceId) (xdr.ClaimableBalanceEntry, error) {
+	hexID, err := xdr.MarshalHex(balanceID)
+	if err != nil {
+		return xdr.ClaimableBalanceEntry{}, errors.Wrapf(err, "invalid balance id in operation %d", o.index)
+	}
+
+	for _, c := range o.changes {
+		if c.Type != xdr.LedgerEntryTypeClaimableBalance || c.Pre == nil || c.Post != nil {
+			continue
+		}
+		cb := c.Pre.Data.MustClaimableBalance()
+		preHexID, err := xdr.MarshalHex(cb.BalanceId)
+		if err != nil {
+			return xdr.ClaimableBalanceEntry{}, errors.Wrapf(err, "invalid balance id in meta changes for operation %d", o.index)
+		}
+		if preHexID == hexID {
+			return cb, nil
+		}
+	}
+	return xdr.ClaimableBalanceEntry{}, errors.Errorf("change not found for balance id %s", hexID)
+}
+
+// liquidityPoolDelta is the change in a liquidity pool's reserves and shares
+// caused by an operation
+type liquidityPoolDelta struct {
+	ReserveA        xdr.Int64
+	ReserveB        xdr.Int64
+	TotalPoolShares xdr.Int64
+}
+
+var errLiquidityPoolChangeNotFound = errors.New("liquidity pool change not found")
+
+// getLiquidityPoolAndProductDelta returns the latest state of the liquidity
+// pool changed by the operation along with the delta of its reserves. When
+// lpID is nil the first liquidity pool change is used.
+func (o *operationWrapper) getLiquidityPoolAndProductDelta(lpID *xdr.PoolId) (*xdr.LiquidityPoolEntry, *liquidityPoolDelta, error) {
+	for _, c := range o.changes {
+		if c.Type != xdr.LedgerEntryTypeLiquidityPool {
+			continue
+		}
+		if lpID != nil && liquidityPoolChangeID(c) != *lpID {
+			continue
+		}
+		return liquidityPoolChangeDelta(c)
+	}
+
+	return nil, nil, errLiquidityPoolChangeNotFound
+}
+
+// liquidityPoolChangeID returns the ID of the pool changed by a liquidity pool
+// change
+func liquidityPoolChangeID(c ingest.Change) xdr.PoolId {
+	if c.Post != nil {
+		return c.Post.Data.MustLiquidityPool().LiquidityPoolId
+	}
+	return c.Pre.Data.MustLiquidityPool().LiquidityPoolId
+}
+
+// liquidityPoolChangeDelta returns the latest state of the pool in a
+// liquidity pool change along with the delta of its reserves
+func liquidityPoolChangeDelta(c ingest.Change) (*xdr.LiquidityPoolEntry, *liquidityPoolDelta, error) {
+	// The delta can be caused by a full removal or full creation of the liquidity pool
+	var lp *xdr.LiquidityPoolEntry
+	var preA, preB, preShares xdr.Int64
+	if c.Pre != nil {
+		lp = c.Pre.Data.LiquidityPool
+		if lp.Body.Type != xdr.LiquidityPoolTypeLiquidityPoolConstantProduct {
+			return nil, nil, errors.Errorf("unexpected liquidity pool body type %d", lp.Body.Type)
+		}
+		cpPre := lp.Body.ConstantProduct
+		preA, preB, preShares = cpPre.ReserveA, cpPre.ReserveB, cpPre.TotalPoolShares
+	}
+	var postA, postB, postShares xdr.Int64
+	if c.Post != nil {
+		lp = c.Post.Data.LiquidityPool
+		if lp.Body.Type != xdr.LiquidityPoolTypeLiquidityPoolConstantProduct {
+			return nil, nil, errors.Errorf("unexpected liquidity pool body type %d", lp.Body.Type)
+		}
+		cpPost := lp.Body.ConstantProduct
+		postA, postB, postShares = cpPost.ReserveA, cpPost.ReserveB, cpPost.TotalPoolShares
+	}
+	delta := &liquidityPoolDelta{
+		ReserveA:        postA - preA,
+		ReserveB:        postB - preB,
+		TotalPoolShares: postShares - preShares,
+	}
+	return lp, delta, nil
+}
+
+// revokedReserveBalanceID returns the ID core gives the claimable balance
+// holding the reserve of asset redeemed from the given pool when the operation
+// revoked the trustor's authorization. It is derived from the (inner)
+// transaction source and sequence number and the operation index.
+func (o *operationWrapper) revokedReserveBalanceID(poolID xdr.PoolId, asset xdr.Asset) (xdr.ClaimableBalanceId, error) {
+	preimage := xdr.HashIdPreimage{
+		Type: xdr.EnvelopeTypeEnvelopeTypePoolRevokeOpId,
+		RevokeId: &xdr.HashIdPreimageRevokeId{
+			SourceAccount:   o.transaction.Envelope.SourceAccount().ToAccountId(),
+			SeqNum:          xdr.SequenceNumber(o.transaction.Envelope.SeqNum()),
+			OpNum:           xdr.Uint32(o.index),
+			LiquidityPoolId: poolID,
+			Asset:           asset,
+		},
+	}
+	raw, err := preimage.MarshalBinary()
+	if err != nil {
+		return xdr.ClaimableBalanceId{}, errors.Wrap(err, "error marshaling revoke id preimage")
+	}
+	hash := xdr.Hash(sha256.Sum256(raw))
+	return xdr.ClaimableBalanceId{
+		Type: xdr.ClaimableBalanceIdTypeClaimableBalanceIdTypeV0,
+		V0:   &hash,
+	}, nil
+}
@@ -0,0 +1,210 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.5
+// 	protoc        v3.12.4
+// source: withobsrvr/flow/effects/v1/transaction.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Transaction is the protobuf form of the per-transaction JSON input. XDR
+// fields carry raw XDR bytes rather than base64.
+type Transaction struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Transaction envelope XDR
+	EnvelopeXdr []byte `protobuf:"bytes,1,opt,name=envelope_xdr,json=envelopeXdr,proto3" json:"envelope_xdr,omitempty"`
+	// Transaction result pair XDR
+	ResultXdr []byte `protobuf:"bytes,2,opt,name=result_xdr,json=resultXdr,proto3" json:"result_xdr,omitempty"`
+	// Transaction meta XDR
+	MetaXdr []byte `protobuf:"bytes,3,opt,name=meta_xdr,json=metaXdr,proto3" json:"meta_xdr,omitempty"`
+	// Optional fee processing LedgerEntryChanges XDR
+	FeeMetaXdr []byte `protobuf:"bytes,4,opt,name=fee_meta_xdr,json=feeMetaXdr,proto3" json:"fee_meta_xdr,omitempty"`
+	// Sequence of the ledger containing the transaction
+	LedgerSequence uint32 `protobuf:"varint,5,opt,name=ledger_sequence,json=ledgerSequence,proto3" json:"ledger_sequence,omitempty"`
+	// Ledger close time in unix seconds
+	LedgerCloseTime int64 `protobuf:"varint,6,opt,name=ledger_close_time,json=ledgerCloseTime,proto3" json:"ledger_close_time,omitempty"`
+	// 1-based application order of the transaction within its ledger
+	TxIndex uint32 `protobuf:"varint,7,opt,name=tx_index,json=txIndex,proto3" json:"tx_index,omitempty"`
+	// Optional 64-character hex transaction hash
+	Hash          string `protobuf:"bytes,8,opt,name=hash,proto3" json:"hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Transaction) Reset() {
+	*x = Transaction{}
+	mi := &file_withobsrvr_flow_effects_v1_transaction_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Transaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Transaction) ProtoMessage() {}
+
+func (x *Transaction) ProtoReflect() protoreflect.Message {
+	mi := &file_withobsrvr_flow_effects_v1_transaction_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Transaction.ProtoReflect.Descriptor instead.
+func (*Transaction) Descriptor() ([]byte, []int) {
+	return file_withobsrvr_flow_effects_v1_transaction_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Transaction) GetEnvelopeXdr() []byte {
+	if x != nil {
+		return x.EnvelopeXdr
+	}
+	return nil
+}
+
+func (x *Transaction) GetResultXdr() []byte {
+	if x != nil {
+		return x.ResultXdr
+	}
+	return nil
+}
+
+func (x *Transaction) GetMetaXdr() []byte {
+	if x != nil {
+		return x.MetaXdr
+	}
+	return nil
+}
+
+func (x *Transaction) GetFeeMetaXdr() []byte {
+	if x != nil {
+		return x.FeeMetaXdr
+	}
+	return nil
+}
+
+func (x *Transaction) GetLedgerSequence() uint32 {
+	if x != nil {
+		return x.LedgerSequence
+	}
+	return 0
+}
+
+func (x *Transaction) GetLedgerCloseTime() int64 {
+	if x != nil {
+		return x.LedgerCloseTime
+	}
+	return 0
+}
+
+func (x *Transaction) GetTxIndex() uint32 {
+	if x != nil {
+		return x.TxIndex
+	}
+	return 0
+}
+
+func (x *Transaction) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+var File_withobsrvr_flow_effects_v1_transaction_proto protoreflect.FileDescriptor
+
+var file_withobsrvr_flow_effects_v1_transaction_proto_rawDesc = string([]byte{
+	0x0a, 0x2c, 0x77, 0x69, 0x74, 0x68, 0x6f, 0x62, 0x73, 0x72, 0x76, 0x72, 0x2f, 0x66, 0x6c, 0x6f,
+	0x77, 0x2f, 0x65, 0x66, 0x66, 0x65, 0x63, 0x74, 0x73, 0x2f, 0x76, 0x31, 0x2f, 0x74, 0x72, 0x61,
+	0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0f,
+	0x66, 0x6c, 0x6f, 0x77, 0x2e, 0x65, 0x66, 0x66, 0x65, 0x63, 0x74, 0x73, 0x2e, 0x76, 0x31, 0x22,
+	0x90, 0x02, 0x0a, 0x0b, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x21, 0x0a, 0x0c, 0x65, 0x6e, 0x76, 0x65, 0x6c, 0x6f, 0x70, 0x65, 0x5f, 0x78, 0x64, 0x72, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x65, 0x6e, 0x76, 0x65, 0x6c, 0x6f, 0x70, 0x65, 0x58,
+	0x64, 0x72, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x5f, 0x78, 0x64, 0x72,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x58, 0x64,
+	0x72, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x5f, 0x78, 0x64, 0x72, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x07, 0x6d, 0x65, 0x74, 0x61, 0x58, 0x64, 0x72, 0x12, 0x20, 0x0a, 0x0c,
+	0x66, 0x65, 0x65, 0x5f, 0x6d, 0x65, 0x74, 0x61, 0x5f, 0x78, 0x64, 0x72, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x0a, 0x66, 0x65, 0x65, 0x4d, 0x65, 0x74, 0x61, 0x58, 0x64, 0x72, 0x12, 0x27,
+	0x0a, 0x0f, 0x6c, 0x65, 0x64, 0x67, 0x65, 0x72, 0x5f, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63,
+	0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0e, 0x6c, 0x65, 0x64, 0x67, 0x65, 0x72, 0x53,
+	0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x2a, 0x0a, 0x11, 0x6c, 0x65, 0x64, 0x67, 0x65,
+	0x72, 0x5f, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0f, 0x6c, 0x65, 0x64, 0x67, 0x65, 0x72, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x54,
+	0x69, 0x6d, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x78, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x74, 0x78, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x12,
+	0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61,
+	0x73, 0x68, 0x42, 0x31, 0x5a, 0x2f, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
+	0x2f, 0x77, 0x69, 0x74, 0x68, 0x4f, 0x62, 0x73, 0x72, 0x76, 0x72, 0x2f, 0x66, 0x6c, 0x6f, 0x77,
+	0x2d, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x6f, 0x72, 0x2d, 0x65, 0x66, 0x66, 0x65, 0x63,
+	0x74, 0x73, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+})
+
+var (
+	file_withobsrvr_flow_effects_v1_transaction_proto_rawDescOnce sync.Once
+	file_withobsrvr_flow_effects_v1_transaction_proto_rawDescData []byte
+)
+
+func file_withobsrvr_flow_effects_v1_transaction_proto_rawDescGZIP() []byte {
+	file_withobsrvr_flow_effects_v1_transaction_proto_rawDescOnce.Do(func() {
+		file_withobsrvr_flow_effects_v1_transaction_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_withobsrvr_flow_effects_v1_transaction_proto_rawDesc), len(file_withobsrvr_flow_effects_v1_transaction_proto_rawDesc)))
+	})
+	return file_withobsrvr_flow_effects_v1_transaction_proto_rawDescData
+}
+
+var file_withobsrvr_flow_effects_v1_transaction_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_withobsrvr_flow_effects_v1_transaction_proto_goTypes = []any{
+	(*Transaction)(nil), // 0: flow.effects.v1.Transaction
+}
+var file_withobsrvr_flow_effects_v1_transaction_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_withobsrvr_flow_effects_v1_transaction_proto_init() }
+func file_withobsrvr_flow_effects_v1_transaction_proto_init() {
+	if File_withobsrvr_flow_effects_v1_transaction_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_withobsrvr_flow_effects_v1_transaction_proto_rawDesc), len(file_withobsrvr_flow_effects_v1_transaction_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_withobsrvr_flow_effects_v1_transaction_proto_goTypes,
+		DependencyIndexes: file_withobsrvr_flow_effects_v1_transaction_proto_depIdxs,
+		MessageInfos:      file_withobsrvr_flow_effects_v1_transaction_proto_msgTypes,
+	}.Build()
+	File_withobsrvr_flow_effects_v1_transaction_proto = out.File
+	file_withobsrvr_flow_effects_v1_transaction_proto_goTypes = nil
+	file_withobsrvr_flow_effects_v1_transaction_proto_depIdxs = nil
+}
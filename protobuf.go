@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/toid"
+	"github.com/stellar/go/xdr"
+	"github.com/withObsrvr/flow-processor-effects/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// contentTypeMetadataKey is the message metadata key naming the encoding of a
+// per-transaction payload. Payloads without it are treated as JSON.
+const contentTypeMetadataKey = "content_type"
+
+// Content types accepted for per-transaction payloads
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+// isProtobufContentType reports whether the message metadata marks the
+// payload as a protobuf encoded pb.Transaction
+func isProtobufContentType(metadata map[string]interface{}) (bool, error) {
+	value, ok := metadata[contentTypeMetadataKey]
+	if !ok || value == nil {
+		return false, nil
+	}
+	contentType, ok := value.(string)
+	if !ok {
+		return false, errors.Errorf("%s metadata must be a string, got %T", contentTypeMetadataKey, value)
+	}
+	switch contentType {
+	case ContentTypeJSON, "":
+		return false, nil
+	case ContentTypeProtobuf, "application/protobuf":
+		return true, nil
+	default:
+		return false, errors.Errorf("unsupported %s %q", contentTypeMetadataKey, contentType)
+	}
+}
+
+// processTransactionProto derives the effects of a single transaction encoded
+// as a pb.Transaction
+func (p *EffectsProcessor) processTransactionProto(ctx context.Context, net networkConfig, payload interface{}) ([]EffectOutput, error) {
+	payloadBytes, ok := payload.([]byte)
+	if !ok {
+		return nil, NewProcessorError(
+			fmt.Errorf("expected payload to be []byte, got %T", payload),
+			ErrorTypeParsing,
+			ErrorSeverityError,
+		)
+	}
+
+	var input pb.Transaction
+	if err := proto.Unmarshal(payloadBytes, &input); err != nil {
+		return nil, NewProcessorError(
+			fmt.Errorf("error unmarshaling protobuf transaction: %w", err),
+			ErrorTypeParsing,
+			ErrorSeverityError,
+		)
+	}
+
+	wrapper, err := p.parseProtoTransaction(&input, net)
+	if err != nil {
+		return nil, err
+	}
+	if err := attachTransactionHash(wrapper, input.GetHash()); err != nil {
+		return nil, err
+	}
+
+	effects, err := p.transformTransactionToEffects(ctx, wrapper)
+	if err != nil {
+		return nil, NewProcessorError(
+			fmt.Errorf("error transforming transaction to effects: %w", err),
+			ErrorTypeProcessing,
+			ErrorSeverityError,
+		).WithTransaction(protoTransactionHash(&input)).WithLedger(wrapper.LedgerSeq)
+	}
+	return effects, nil
+}
+
+// protoTransactionHash returns the hash given in the input, for error reporting
+func protoTransactionHash(in *pb.Transaction) string {
+	if in.GetHash() == "" {
+		return "unknown"
+	}
+	return in.GetHash()
+}
+
+// parseProtoTransaction validates a protobuf transaction input and converts
+// it to the wrapper type needed by effects logic. As with JSON input, every
+// invalid field is reported at once.
+func (p *EffectsProcessor) parseProtoTransaction(in *pb.Transaction, net networkConfig) (*TransactionWrapper, error) {
+	invalid := fieldErrors{}
+
+	if err := checkRange(int64(in.GetLedgerSequence()), 1, math.MaxInt32); err != nil {
+		invalid.add("ledger_sequence", err)
+	}
+	if err := checkRange(int64(in.GetTxIndex()), 1, toid.TransactionMask); err != nil {
+		invalid.add("tx_index", err)
+	}
+	// proto3 can't tell an unset close time from 0, so 0 is treated as missing
+	if in.GetLedgerCloseTime() == 0 {
+		invalid.add("ledger_close_time", errMissingField)
+	} else if err := checkRange(in.GetLedgerCloseTime(), 1, math.MaxInt64); err != nil {
+		invalid.add("ledger_close_time", err)
+	}
+
+	if in.GetHash() != "" {
+		if err := checkTransactionHash(in.GetHash()); err != nil {
+			invalid.add("hash", err)
+		}
+	}
+
+	var envelope xdr.TransactionEnvelope
+	if err := decodeRawXDRField(in.GetEnvelopeXdr(), true, &envelope); err != nil {
+		invalid.add("envelope_xdr", err)
+	} else if err := checkOperationCount(envelope); err != nil {
+		invalid.add("envelope_xdr", err)
+	}
+
+	var resultPair xdr.TransactionResultPair
+	if err := decodeRawXDRField(in.GetResultXdr(), true, &resultPair); err != nil {
+		invalid.add("result_xdr", err)
+	}
+
+	var transactionMeta xdr.TransactionMeta
+	if err := decodeRawXDRField(in.GetMetaXdr(), true, &transactionMeta); err != nil {
+		invalid.add("meta_xdr", err)
+	} else if err := checkTransactionMetaChanges(transactionMeta); err != nil {
+		invalid.add("meta_xdr", err)
+	}
+
+	var feeChanges xdr.LedgerEntryChanges
+	if err := decodeRawXDRField(in.GetFeeMetaXdr(), false, &feeChanges); err != nil {
+		invalid.add("fee_meta_xdr", err)
+	} else if err := checkLedgerEntryChanges(feeChanges); err != nil {
+		invalid.add("fee_meta_xdr", err)
+	}
+
+	if len(invalid) > 0 {
+		return nil, invalid.processorError().
+			WithTransaction(protoTransactionHash(in)).
+			WithLedger(in.GetLedgerSequence())
+	}
+
+	return &TransactionWrapper{
+		Transaction: ingest.LedgerTransaction{
+			Index:      in.GetTxIndex(),
+			Envelope:   envelope,
+			Result:     resultPair,
+			UnsafeMeta: transactionMeta,
+			FeeChanges: feeChanges,
+		},
+		LedgerSeq:  in.GetLedgerSequence(),
+		Network:    net.Name,
+		Passphrase: net.Passphrase,
+		CloseTime:  time.Unix(in.GetLedgerCloseTime(), 0).UTC(),
+	}, nil
+}
+
+// decodeRawXDRField unmarshals a raw XDR input field into dest
+func decodeRawXDRField(value []byte, required bool, dest interface{}) error {
+	if len(value) == 0 {
+		if required {
+			return errMissingField
+		}
+		return nil
+	}
+	if err := xdr.SafeUnmarshal(value, dest); err != nil {
+		return errors.Wrap(err, "invalid XDR")
+	}
+	return nil
+}
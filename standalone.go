@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/withObsrvr/pluginapi"
+)
+
+// main runs a standalone backfill, writing effects to stdout as JSON lines.
+// It is not used when the package is built as a Flow plugin.
+//
+//	go build -o effects-backfill .
+//	./effects-backfill -config config.json -datastore ./ledgers -start 100 -end 200
+func main() {
+	defaults := DefaultBackfillConfig()
+
+	configPath := flag.String("config", "", "JSON file with the processor configuration (required)")
+	dataStorePath := flag.String("datastore", "", "directory holding galexie ledger files (required)")
+	start := flag.Uint("start", 0, "first ledger to backfill (required)")
+	end := flag.Uint("end", 0, "last ledger to backfill, inclusive (required)")
+	network := flag.String("network", "", "configured network of the ledger files, defaults to the default network")
+	ledgersPerFile := flag.Uint("ledgers-per-file", uint(defaults.LedgersPerFile), "ledgers per file")
+	filesPerPartition := flag.Uint("files-per-partition", uint(defaults.FilesPerPartition), "files per partition directory")
+	bufferSize := flag.Uint("buffer-size", uint(defaults.BufferSize), "ledger files read ahead")
+	numWorkers := flag.Uint("workers", uint(defaults.NumWorkers), "parallel file readers")
+	flag.Parse()
+
+	if *configPath == "" {
+		log.Fatal("-config is required")
+	}
+	config, err := readProcessorConfig(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	processor := &EffectsProcessor{}
+	if err := processor.Initialize(config); err != nil {
+		log.Fatal(err)
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	processor.RegisterConsumer(&jsonLinesConsumer{w: out})
+
+	backfill := defaults
+	backfill.DataStorePath = *dataStorePath
+	backfill.StartLedger = uint32(*start)
+	backfill.EndLedger = uint32(*end)
+	backfill.Network = *network
+	backfill.LedgersPerFile = uint32(*ledgersPerFile)
+	backfill.FilesPerPartition = uint32(*filesPerPartition)
+	backfill.BufferSize = uint32(*bufferSize)
+	backfill.NumWorkers = uint32(*numWorkers)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	backfillErr := processor.Backfill(ctx, backfill)
+	stop()
+
+	// Flush whatever was written before reporting, and fail if the buffered
+	// effects could not be written out
+	if err := out.Flush(); err != nil && backfillErr == nil {
+		backfillErr = errors.Wrap(err, "error writing effects")
+	}
+	if backfillErr != nil {
+		log.Fatal(backfillErr)
+	}
+}
+
+// readProcessorConfig reads the processor configuration from a JSON file
+func readProcessorConfig(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading config file")
+	}
+	var config map[string]interface{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, errors.Wrap(err, "error parsing config file")
+	}
+	return config, nil
+}
+
+// jsonLinesConsumer writes each effect payload as a line of JSON
+type jsonLinesConsumer struct {
+	w io.Writer
+}
+
+func (c *jsonLinesConsumer) Name() string {
+	return "flow/consumer/json-lines"
+}
+
+func (c *jsonLinesConsumer) Version() string {
+	return "0.1.0"
+}
+
+func (c *jsonLinesConsumer) Type() pluginapi.PluginType {
+	return pluginapi.ConsumerPlugin
+}
+
+func (c *jsonLinesConsumer) Initialize(config map[string]interface{}) error {
+	return nil
+}
+
+func (c *jsonLinesConsumer) Process(ctx context.Context, msg pluginapi.Message) error {
+	payload, ok := msg.Payload.([]byte)
+	if !ok {
+		return fmt.Errorf("expected payload to be []byte, got %T", msg.Payload)
+	}
+	if _, err := c.w.Write(append(payload, '\n')); err != nil {
+		return errors.Wrap(err, "error writing effect")
+	}
+	return nil
+}
+
+func (c *jsonLinesConsumer) Close() error {
+	return nil
+}
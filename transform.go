@@ -1,13 +1,16 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"log"
 	"time"
 
-	"github.com/guregu/null"
 	"github.com/pkg/errors"
 	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/toid"
 	"github.com/stellar/go/xdr"
 )
 
@@ -15,23 +18,18 @@ import (
 type TransactionWrapper struct {
 	Transaction ingest.LedgerTransaction
 	LedgerSeq   uint32
+	Network     string
 	Passphrase  string
 	CloseTime   time.Time
 }
 
 // transformTransactionToEffects adapts the original effects transformation logic
-func (p *EffectsProcessor) transformTransactionToEffects(ctx context.Context, transaction map[string]interface{}) ([]EffectOutput, error) {
+func (p *EffectsProcessor) transformTransactionToEffects(ctx context.Context, wrapper *TransactionWrapper) ([]EffectOutput, error) {
 	// Check for context cancellation
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 
-	// Parse the transaction data into the expected format
-	wrapper, err := p.parseTransaction(transaction)
-	if err != nil {
-		return nil, errors.Wrap(err, "error parsing transaction data")
-	}
-
 	// Generate effect records using the adapted logic from the original code
 	effects, err := p.generateEffects(wrapper)
 	if err != nil {
@@ -41,108 +39,237 @@ func (p *EffectsProcessor) transformTransactionToEffects(ctx context.Context, tr
 	return effects, nil
 }
 
-// parseTransaction converts a generic transaction map to the wrapper type needed by effects logic
-func (p *EffectsProcessor) parseTransaction(tx map[string]interface{}) (*TransactionWrapper, error) {
-	// This is a simplified example - in a real implementation, we would:
-	// 1. Extract the transaction envelope XDR
-	// 2. Parse it into an ingest.LedgerTransaction
-	// 3. Get ledger sequence and close time
-	// 4. Return a fully populated TransactionWrapper
+// parseTransaction validates a transaction input and converts it to the
+// wrapper type needed by effects logic. Every invalid field is reported in
+// the returned ProcessorError rather than only the first one.
+func (p *EffectsProcessor) parseTransaction(in *TransactionInput, net networkConfig) (*TransactionWrapper, error) {
+	invalid := fieldErrors{}
 
-	// For the simplified example:
-	txEnvelopeXDR, ok := tx["envelope_xdr"].(string)
-	if !ok {
-		return nil, errors.New("missing or invalid envelope_xdr in transaction")
-	}
-
-	// Parse the transaction envelope XDR
-	var envelope xdr.TransactionEnvelope
-	if err := xdr.SafeUnmarshalBase64(txEnvelopeXDR, &envelope); err != nil {
-		return nil, errors.Wrap(err, "error unmarshaling transaction envelope XDR")
+	ledgerSeq, err := parseLedgerSequence(in.LedgerSequence)
+	if err != nil {
+		invalid.add("ledger_sequence", err)
 	}
 
-	// Extract ledger sequence
-	ledgerSeq, ok := tx["ledger_sequence"].(float64)
-	if !ok {
-		return nil, errors.New("missing or invalid ledger_sequence in transaction")
+	txIndex, err := parseTxIndex(in.TxIndex)
+	if err != nil {
+		invalid.add("tx_index", err)
 	}
 
-	// Extract close time
-	closeTimeStr, ok := tx["ledger_close_time"].(string)
-	if !ok {
-		return nil, errors.New("missing or invalid ledger_close_time in transaction")
-	}
-	closeTime, err := time.Parse(time.RFC3339, closeTimeStr)
+	closeTime, err := parseCloseTime(in.LedgerCloseTime)
 	if err != nil {
-		return nil, errors.Wrap(err, "error parsing ledger close time")
+		invalid.add("ledger_close_time", err)
 	}
 
-	// Extract result XDR and meta XDR
-	resultXDR, ok := tx["result_xdr"].(string)
-	if !ok {
-		return nil, errors.New("missing or invalid result_xdr in transaction")
+	if hash, err := parseStringField(in.Hash, false); err != nil {
+		invalid.add("hash", err)
+	} else if hash != "" {
+		if err := checkTransactionHash(hash); err != nil {
+			invalid.add("hash", err)
+		}
 	}
 
-	metaXDR, ok := tx["meta_xdr"].(string)
-	if !ok {
-		return nil, errors.New("missing or invalid meta_xdr in transaction")
+	var envelope xdr.TransactionEnvelope
+	if err := decodeXDRField(in.EnvelopeXDR, true, &envelope); err != nil {
+		invalid.add("envelope_xdr", err)
+	} else if err := checkOperationCount(envelope); err != nil {
+		invalid.add("envelope_xdr", err)
 	}
 
-	// Parse result and meta XDR
 	var resultPair xdr.TransactionResultPair
-	if err := xdr.SafeUnmarshalBase64(resultXDR, &resultPair); err != nil {
-		return nil, errors.Wrap(err, "error unmarshaling result XDR")
+	if err := decodeXDRField(in.ResultXDR, true, &resultPair); err != nil {
+		invalid.add("result_xdr", err)
 	}
 
 	var transactionMeta xdr.TransactionMeta
-	if err := xdr.SafeUnmarshalBase64(metaXDR, &transactionMeta); err != nil {
-		return nil, errors.Wrap(err, "error unmarshaling meta XDR")
+	if err := decodeXDRField(in.MetaXDR, true, &transactionMeta); err != nil {
+		invalid.add("meta_xdr", err)
+	} else if err := checkTransactionMetaChanges(transactionMeta); err != nil {
+		invalid.add("meta_xdr", err)
+	}
+
+	// Fee processing changes are optional; without them fee validation is skipped
+	var feeChanges xdr.LedgerEntryChanges
+	if err := decodeXDRField(in.FeeMetaXDR, false, &feeChanges); err != nil {
+		invalid.add("fee_meta_xdr", err)
+	} else if err := checkLedgerEntryChanges(feeChanges); err != nil {
+		invalid.add("fee_meta_xdr", err)
+	}
+
+	if len(invalid) > 0 {
+		return nil, invalid.processorError().
+			WithTransaction(in.transactionHash()).
+			WithLedger(ledgerSeq)
 	}
 
 	// Create a LedgerTransaction
 	lt := ingest.LedgerTransaction{
-		Index:      uint32(0), // We might not have this information, default to 0
+		Index:      txIndex,
 		Envelope:   envelope,
 		Result:     resultPair,
 		UnsafeMeta: transactionMeta,
-		FeeChanges: xdr.LedgerEntryChanges{}, // We might not have this information
+		FeeChanges: feeChanges,
 	}
 
 	return &TransactionWrapper{
 		Transaction: lt,
-		LedgerSeq:   uint32(ledgerSeq),
-		Passphrase:  p.networkPassphrase,
+		LedgerSeq:   ledgerSeq,
+		Network:     net.Name,
+		Passphrase:  net.Passphrase,
 		CloseTime:   closeTime,
 	}, nil
 }
 
-// generateEffects adapts the Stellar effect generation logic to create effect records
-func (p *EffectsProcessor) generateEffects(wrapper *TransactionWrapper) ([]EffectOutput, error) {
-	// This is a simplified version of the original transformation logic
-	// In a real implementation, we would:
-	// 1. Iterate through operations in the transaction
-	// 2. Generate effects for each operation using the original effects.Effects function
-	// 3. Return the combined effects
-
-	// For now, we'll create a sample effect to demonstrate the structure
-	// In a production implementation, adapt the full effect.TransformEffect logic
-	sampleEffect := EffectOutput{
-		Address:        "SAMPLE_ADDRESS", // Would be extracted from operation
-		AddressMuxed:   null.NewString("SAMPLE_MUXED_ADDRESS", true),
-		OperationID:    12345,                                       // Would be calculated based on transaction and operation index
-		Details:        map[string]interface{}{"info": "sample_op"}, // Would contain operation-specific details
-		Type:           int32(EffectAccountCreated),                 // Would be determined by the operation type
-		TypeString:     EffectTypeNames[EffectAccountCreated],       // Would be looked up from the operation type
-		LedgerClosed:   wrapper.CloseTime,
-		LedgerSequence: wrapper.LedgerSeq,
-		EffectIndex:    0,
-		EffectId:       fmt.Sprintf("%d-%d", 12345, 0), // Would be constructed from operation ID and effect index
-	}
-
-	// In a real implementation, this would call the adapted TransformEffect function
-	// and generate multiple effects based on the transaction operations
-	return []EffectOutput{sampleEffect}, nil
+// checkOperationCount checks that every operation of the envelope can be
+// addressed by an operation ID
+func checkOperationCount(envelope xdr.TransactionEnvelope) error {
+	if len(envelope.Operations()) > toid.OperationMask {
+		return errors.Errorf("transaction has %d operations, more than an operation ID can address", len(envelope.Operations()))
+	}
+	return nil
+}
+
+// checkLedgerEntryChanges checks that every updated or removed entry directly
+// follows the state of the same entry. The SDK reads the change before an
+// update or removal as its pre-state without checking it, and panics when it
+// is missing.
+func checkLedgerEntryChanges(changes xdr.LedgerEntryChanges) error {
+	for i, change := range changes {
+		var key xdr.LedgerKey
+		switch change.Type {
+		case xdr.LedgerEntryChangeTypeLedgerEntryUpdated:
+			updated := change.MustUpdated()
+			var err error
+			if key, err = updated.LedgerKey(); err != nil {
+				return errors.Wrapf(err, "invalid ledger entry in change %d", i)
+			}
+		case xdr.LedgerEntryChangeTypeLedgerEntryRemoved:
+			key = change.MustRemoved()
+		default:
+			continue
+		}
+
+		if i == 0 || changes[i-1].Type != xdr.LedgerEntryChangeTypeLedgerEntryState {
+			return errors.Errorf("change %d (%s) does not follow the state of its ledger entry", i, change.Type)
+		}
+		state := changes[i-1].MustState()
+		stateKey, err := state.LedgerKey()
+		if err != nil {
+			return errors.Wrapf(err, "invalid ledger entry in change %d", i-1)
+		}
+		keyBytes, err := key.MarshalBinary()
+		if err != nil {
+			return errors.Wrapf(err, "invalid ledger key in change %d", i)
+		}
+		stateKeyBytes, err := stateKey.MarshalBinary()
+		if err != nil {
+			return errors.Wrapf(err, "invalid ledger key in change %d", i-1)
+		}
+		if !bytes.Equal(keyBytes, stateKeyBytes) {
+			return errors.Errorf("change %d (%s) is for a different ledger entry than the state before it", i, change.Type)
+		}
+	}
+	return nil
 }
 
-// In a real implementation, we would implement the wrapper for effect.TransformEffect here,
-// adapting all the operation processing logic to our new context.
+// checkTransactionMetaChanges checks the ledger entry changes of the
+// transaction and of every operation in the meta with checkLedgerEntryChanges
+func checkTransactionMetaChanges(meta xdr.TransactionMeta) error {
+	var (
+		txChanges  []xdr.LedgerEntryChanges
+		operations []xdr.OperationMeta
+	)
+	switch meta.V {
+	case 1:
+		txChanges = []xdr.LedgerEntryChanges{meta.MustV1().TxChanges}
+		operations = meta.MustV1().Operations
+	case 2:
+		txChanges = []xdr.LedgerEntryChanges{meta.MustV2().TxChangesBefore, meta.MustV2().TxChangesAfter}
+		operations = meta.MustV2().Operations
+	case 3:
+		txChanges = []xdr.LedgerEntryChanges{meta.MustV3().TxChangesBefore, meta.MustV3().TxChangesAfter}
+		operations = meta.MustV3().Operations
+	default:
+		// Version 0 meta is rejected when the operation changes are read
+		return nil
+	}
+
+	for _, changes := range txChanges {
+		if err := checkLedgerEntryChanges(changes); err != nil {
+			return errors.Wrap(err, "invalid transaction changes")
+		}
+	}
+	for i, operation := range operations {
+		if err := checkLedgerEntryChanges(operation.Changes); err != nil {
+			return errors.Wrapf(err, "invalid changes for operation %d", i)
+		}
+	}
+	return nil
+}
+
+// decodeXDRField unmarshals a base64 XDR input field into dest
+func decodeXDRField(raw json.RawMessage, required bool, dest interface{}) error {
+	value, err := parseStringField(raw, required)
+	if err != nil || value == "" {
+		return err
+	}
+	if err := xdr.SafeUnmarshalBase64(value, dest); err != nil {
+		return errors.Wrap(err, "invalid XDR")
+	}
+	return nil
+}
+
+// generateEffects walks every operation in the transaction and derives its effects
+func (p *EffectsProcessor) generateEffects(wrapper *TransactionWrapper) ([]EffectOutput, error) {
+	// A fee mismatch points at bad upstream data for this transaction only,
+	// so it is reported without aborting the rest of the ledger
+	if err := validateFeeChanges(wrapper.Transaction); err != nil {
+		log.Printf("EffectsProcessor: %v", NewProcessorError(
+			fmt.Errorf("fee changes do not match the transaction result: %w", err),
+			ErrorTypeProcessing,
+			ErrorSeverityWarning,
+		).WithTransaction(wrapper.Transaction.Hash.HexString()).WithLedger(wrapper.LedgerSeq))
+	}
+
+	// Failed transactions don't have operation effects
+	if !wrapper.Transaction.Result.Successful() {
+		return p.failedTransactionEffects(wrapper)
+	}
+
+	effects := []EffectOutput{}
+
+	results, ok := wrapper.Transaction.Result.OperationResults()
+	if !ok {
+		return nil, errors.New("transaction result does not contain operation results")
+	}
+
+	for opi, op := range wrapper.Transaction.Envelope.Operations() {
+		if opi >= len(results) {
+			return nil, errors.Errorf("missing result for operation %d", opi)
+		}
+
+		changes, err := wrapper.Transaction.GetOperationChanges(uint32(opi))
+		if err != nil {
+			return nil, errors.Wrapf(err, "error reading changes for operation %d", opi)
+		}
+
+		operation := &operationWrapper{
+			index:          uint32(opi),
+			transaction:    wrapper.Transaction,
+			operation:      op,
+			result:         results[opi],
+			changes:        changes,
+			ledgerSequence: wrapper.LedgerSeq,
+			network:        wrapper.Passphrase,
+			networkName:    wrapper.Network,
+			ledgerClosed:   wrapper.CloseTime,
+		}
+
+		opEffects, err := operationEffects(operation)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error reading operation %d effects", operation.ID())
+		}
+		effects = append(effects, opEffects...)
+	}
+
+	return effects, nil
+}